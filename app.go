@@ -10,6 +10,7 @@ import (
 	"strconv"
 	"sync"
 	"syscall"
+	"time"
 
 	"github.com/fasthttp/router"
 	"github.com/nspcc-dev/neo-go/cli/flags"
@@ -69,6 +70,11 @@ type (
 
 	GateMetricsProvider interface {
 		SetHealth(int32)
+		ResolverCacheHit()
+		ResolverCacheMiss()
+		AddArchiveObject(size uint64)
+		AddArchiveFailure()
+		ObserveArchiveDuration(time.Duration)
 		Unregister()
 	}
 )
@@ -169,8 +175,8 @@ func newApp(ctx context.Context, opt ...Option) App {
 	}
 
 	a.initAppSettings()
-	a.initResolver()
 	a.initMetrics()
+	a.initResolver()
 
 	return a
 }
@@ -196,6 +202,13 @@ func (a *app) getResolverConfig() ([]string, *resolver.Config) {
 	resolveCfg := &resolver.Config{
 		NeoFS:      resolver.NewNeoFSResolver(a.pool),
 		RPCAddress: a.cfg.GetString(cfgRPCEndpoint),
+		Static:     a.cfg.GetStringMapString(cfgResolveStatic),
+		Cache: resolver.CacheConfig{
+			Size:        a.cfg.GetInt(cfgResolveCacheSize),
+			TTL:         a.cfg.GetDuration(cfgResolveCacheTTL),
+			NegativeTTL: a.cfg.GetDuration(cfgResolveCacheNegativeTTL),
+		},
+		Metrics: a.metrics,
 	}
 
 	order := a.cfg.GetStringSlice(cfgResolveOrder)
@@ -247,6 +260,61 @@ func (m *gateMetrics) SetHealth(status int32) {
 	m.provider.SetHealth(status)
 }
 
+func (m *gateMetrics) ResolverCacheHit() {
+	m.mu.RLock()
+	if !m.enabled {
+		m.mu.RUnlock()
+		return
+	}
+	m.mu.RUnlock()
+
+	m.provider.ResolverCacheHit()
+}
+
+func (m *gateMetrics) ResolverCacheMiss() {
+	m.mu.RLock()
+	if !m.enabled {
+		m.mu.RUnlock()
+		return
+	}
+	m.mu.RUnlock()
+
+	m.provider.ResolverCacheMiss()
+}
+
+func (m *gateMetrics) AddArchiveObject(size uint64) {
+	m.mu.RLock()
+	if !m.enabled {
+		m.mu.RUnlock()
+		return
+	}
+	m.mu.RUnlock()
+
+	m.provider.AddArchiveObject(size)
+}
+
+func (m *gateMetrics) AddArchiveFailure() {
+	m.mu.RLock()
+	if !m.enabled {
+		m.mu.RUnlock()
+		return
+	}
+	m.mu.RUnlock()
+
+	m.provider.AddArchiveFailure()
+}
+
+func (m *gateMetrics) ObserveArchiveDuration(d time.Duration) {
+	m.mu.RLock()
+	if !m.enabled {
+		m.mu.RUnlock()
+		return
+	}
+	m.mu.RUnlock()
+
+	m.provider.ObserveArchiveDuration(d)
+}
+
 func (m *gateMetrics) Shutdown() {
 	m.mu.Lock()
 	if m.enabled {
@@ -340,7 +408,7 @@ func (a *app) setHealthStatus() {
 
 func (a *app) Serve(ctx context.Context) {
 	uploadRoutes := uploader.New(ctx, a.AppParams(), a.settings.Uploader)
-	downloadRoutes := downloader.New(ctx, a.AppParams(), a.settings.Downloader)
+	downloadRoutes := downloader.New(ctx, a.AppParams(), a.settings.Downloader, a.metrics)
 
 	// Configure router.
 	a.configureRouter(uploadRoutes, downloadRoutes)
@@ -415,7 +483,31 @@ func (a *app) configReload() {
 
 func (a *app) updateSettings() {
 	a.settings.Uploader.SetDefaultTimestamp(a.cfg.GetBool(cfgUploaderHeaderEnableDefaultTimestamp))
+	a.settings.Uploader.SetDetectContentType(a.cfg.GetBool(cfgUploaderHeaderDetectContentType))
+	a.settings.Uploader.SetResumableEnabled(a.cfg.GetBool(cfgResumableUploadEnabled))
+	a.settings.Uploader.SetResumableDir(a.cfg.GetString(cfgResumableUploadDir))
+	a.settings.Uploader.SetResumableMaxSize(a.cfg.GetUint64(cfgResumableUploadMaxSize))
+	a.settings.Uploader.SetResumableMaxTotalSize(a.cfg.GetUint64(cfgResumableUploadMaxTotalSize))
+	a.settings.Uploader.SetResumableLifetime(a.cfg.GetDuration(cfgResumableUploadLifetime))
+	a.settings.Uploader.SetExtractDir(a.cfg.GetString(cfgExtractDir))
+	a.settings.Uploader.SetExtractMaxSize(a.cfg.GetUint64(cfgExtractMaxSize))
 	a.settings.Downloader.SetZipCompression(a.cfg.GetBool(cfgZipCompression))
+	a.settings.Downloader.SetArchiveWorkers(a.cfg.GetInt(cfgZipWorkers))
+	a.settings.Downloader.SetArchiveMemoryBudget(a.cfg.GetUint64(cfgZipMemoryBudget))
+	if err := a.settings.Downloader.SetArchiveErrorPolicy(a.cfg.GetString(cfgZipOnError)); err != nil {
+		a.log.Warn("invalid archive error policy, the previous one is used",
+			zap.String("policy", a.settings.Downloader.ArchiveErrorPolicy()), zap.Error(err))
+	}
+	if err := a.settings.Downloader.SetMultipleObjectsPolicy(a.cfg.GetString(cfgGetByAttributeMultipleObjects)); err != nil {
+		a.log.Warn("invalid multiple objects policy, the previous one is used",
+			zap.String("policy", a.settings.Downloader.MultipleObjectsPolicy()), zap.Error(err))
+	}
+	a.settings.Downloader.SetWebsiteEnabled(a.cfg.GetBool(cfgWebsiteEnabled))
+	a.settings.Downloader.SetWebsiteContainers(a.cfg.GetStringSlice(cfgWebsiteContainers))
+	a.settings.Downloader.SetWebsiteIndexDocument(a.cfg.GetString(cfgWebsiteIndexDocument))
+	a.settings.Downloader.SetWebsiteErrorDocument(a.cfg.GetString(cfgWebsiteErrorDocument))
+	a.settings.Downloader.SetWebsiteCache(a.cfg.GetInt(cfgWebsiteCacheSize), a.cfg.GetDuration(cfgWebsiteCacheTTL))
+	a.settings.Downloader.SetVirtualHosts(a.cfg.GetStringMapString(cfgVirtualHostsMap), a.cfg.GetStringSlice(cfgVirtualHostsDomains))
 }
 
 func (a *app) startServices() {
@@ -449,17 +541,65 @@ func (a *app) configureRouter(uploadRoutes *uploader.Uploader, downloadRoutes *d
 		response.Error(r, "Method Not Allowed", fasthttp.StatusMethodNotAllowed)
 	}
 	r.POST("/upload/{cid}", a.logger(uploadRoutes.Upload))
+	r.PUT("/upload/{cid}", a.logger(uploadRoutes.UploadRaw))
 	a.log.Info("added path /upload/{cid}")
+	r.OPTIONS("/resumable/{cid}", a.logger(uploadRoutes.ResumableOptions))
+	r.POST("/resumable/{cid}", a.logger(uploadRoutes.ResumableCreate))
+	r.HEAD("/resumable/{cid}/{id}", a.logger(uploadRoutes.ResumableOffset))
+	r.PATCH("/resumable/{cid}/{id}", a.logger(uploadRoutes.ResumablePatch))
+	r.DELETE("/resumable/{cid}/{id}", a.logger(uploadRoutes.ResumableDelete))
+	a.log.Info("added path /resumable/{cid}")
 	r.GET("/get/{cid}/{oid}", a.logger(downloadRoutes.DownloadByAddress))
 	r.HEAD("/get/{cid}/{oid}", a.logger(downloadRoutes.HeadByAddress))
 	a.log.Info("added path /get/{cid}/{oid}")
+	r.GET("/meta/{cid}/{oid}", a.logger(downloadRoutes.DownloadMeta))
+	a.log.Info("added path /meta/{cid}/{oid}")
 	r.GET("/get_by_attribute/{cid}/{attr_key}/{attr_val:*}", a.logger(downloadRoutes.DownloadByAttribute))
 	r.HEAD("/get_by_attribute/{cid}/{attr_key}/{attr_val:*}", a.logger(downloadRoutes.HeadByAttribute))
 	a.log.Info("added path /get_by_attribute/{cid}/{attr_key}/{attr_val:*}")
 	r.GET("/zip/{cid}/{prefix:*}", a.logger(downloadRoutes.DownloadZipped))
+	r.POST("/zip/{cid}", a.logger(downloadRoutes.DownloadZippedList))
 	a.log.Info("added path /zip/{cid}/{prefix}")
+	r.GET("/tar/{cid}/{prefix:*}", a.logger(downloadRoutes.DownloadTar))
+	r.POST("/tar/{cid}", a.logger(downloadRoutes.DownloadTarList))
+	a.log.Info("added path /tar/{cid}/{prefix}")
+	r.GET("/site/{cid}/{path:*}", a.logger(downloadRoutes.DownloadWebsite))
+	r.HEAD("/site/{cid}/{path:*}", a.logger(downloadRoutes.HeadWebsite))
+	a.log.Info("added path /site/{cid}/{path}")
+	r.GET("/search/{cid}", a.logger(downloadRoutes.Search))
+	a.log.Info("added path /search/{cid}")
+	r.GET("/browse/{cid}/{prefix:*}", a.logger(downloadRoutes.Browse))
+	a.log.Info("added path /browse/{cid}/{prefix}")
+
+	a.webServer.Handler = a.virtualHosts(r.Handler, downloadRoutes)
+}
+
+// virtualHosts serves GET and HEAD requests to the hosts bound to containers
+// by FilePath attribute, other requests are passed to the next handler.
+func (a *app) virtualHosts(next fasthttp.RequestHandler, downloadRoutes *downloader.Downloader) fasthttp.RequestHandler {
+	getHandler := a.logger(downloadRoutes.DownloadByHost)
+	headHandler := a.logger(downloadRoutes.HeadByHost)
+
+	return func(c *fasthttp.RequestCtx) {
+		if !c.IsGet() && !c.IsHead() {
+			next(c)
+			return
+		}
 
-	a.webServer.Handler = r.Handler
+		cnr, ok := a.settings.Downloader.VirtualHostContainer(string(c.Host()))
+		if !ok {
+			next(c)
+			return
+		}
+
+		c.SetUserValue("cid", cnr)
+
+		if c.IsHead() {
+			headHandler(c)
+			return
+		}
+		getHandler(c)
+	}
 }
 
 func (a *app) logger(h fasthttp.RequestHandler) fasthttp.RequestHandler {
@@ -0,0 +1,716 @@
+package downloader
+
+import (
+	"archive/tar"
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/nspcc-dev/neofs-http-gw/response"
+	"github.com/nspcc-dev/neofs-http-gw/tokens"
+	"github.com/nspcc-dev/neofs-http-gw/utils"
+	"github.com/nspcc-dev/neofs-sdk-go/bearer"
+	"github.com/nspcc-dev/neofs-sdk-go/client"
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+)
+
+// Supported archive formats.
+const (
+	formatZip   = "zip"
+	formatTar   = "tar"
+	formatTarGz = "tar.gz"
+)
+
+// archiveFileMode is a mode of regular files in archive.
+const archiveFileMode = 0644
+
+const (
+	// archiveFormatParam is a query parameter to choose archive format.
+	archiveFormatParam = "format"
+	// archiveManifestParam is a query parameter to add manifest to archive.
+	archiveManifestParam = "manifest"
+	// archiveManifestName is a name of manifest file in archive.
+	archiveManifestName = "manifest.json"
+)
+
+type (
+	// archiveWriter writes objects to the archive of some format.
+	archiveWriter interface {
+		// createFile adds the file to the archive and returns writer
+		// for its content.
+		createFile(f archiveFile) (io.Writer, error)
+		flush() error
+		close() error
+	}
+
+	// archiveFile describes the file in the archive. Modification time is
+	// unset if it's zero.
+	archiveFile struct {
+		name     string
+		size     uint64
+		modified time.Time
+	}
+
+	tarArchive struct {
+		w  *tar.Writer
+		gz *gzip.Writer
+	}
+)
+
+// newArchiveWriter creates archive writer of the format. Compression is used
+// by zip archives only.
+func newArchiveWriter(format string, compress bool, w io.Writer) archiveWriter {
+	switch format {
+	case formatTar:
+		return &tarArchive{w: tar.NewWriter(w)}
+	case formatTarGz:
+		gz := gzip.NewWriter(w)
+		return &tarArchive{w: tar.NewWriter(gz), gz: gz}
+	default:
+		return newZipArchive(w, compress)
+	}
+}
+
+func (a *tarArchive) createFile(f archiveFile) (io.Writer, error) {
+	modified := f.modified
+	if modified.IsZero() {
+		modified = time.Unix(0, 0)
+	}
+
+	err := a.w.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     f.name,
+		Mode:     archiveFileMode,
+		Size:     int64(f.size),
+		ModTime:  modified.UTC(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return a.w, nil
+}
+
+func (a *tarArchive) flush() error {
+	if err := a.w.Flush(); err != nil {
+		return err
+	}
+	if a.gz != nil {
+		return a.gz.Flush()
+	}
+	return nil
+}
+
+func (a *tarArchive) close() error {
+	if err := a.w.Close(); err != nil {
+		return err
+	}
+	if a.gz != nil {
+		return a.gz.Close()
+	}
+	return nil
+}
+
+// archiveContentType returns Content-Type and file name extension of the
+// archive format.
+func archiveContentType(format string) (string, string) {
+	switch format {
+	case formatTar:
+		return "application/x-tar", "tar"
+	case formatTarGz:
+		return "application/gzip", "tar.gz"
+	default:
+		return "application/zip", "zip"
+	}
+}
+
+// DownloadZipped handles zip by prefix requests.
+func (d *Downloader) DownloadZipped(c *fasthttp.RequestCtx) {
+	d.downloadArchive(c, formatZip)
+}
+
+// DownloadTar handles tar by prefix requests.
+func (d *Downloader) DownloadTar(c *fasthttp.RequestCtx) {
+	d.downloadArchive(c, formatTar)
+}
+
+// downloadArchive streams objects with the names starting with the prefix in
+// the archive. The format of the archive can be overridden by the format query
+// parameter.
+func (d *Downloader) downloadArchive(c *fasthttp.RequestCtx, format string) {
+	scid, _ := c.UserValue("cid").(string)
+	prefix, _ := url.QueryUnescape(c.UserValue("prefix").(string))
+	log := d.log.With(zap.String("cid", scid), zap.String("prefix", prefix))
+
+	format, containerID, ok := d.prepareArchive(c, log, scid, format)
+	if !ok {
+		return
+	}
+
+	var ids []oid.ID
+	for _, filters := range archiveSearchFilters(prefix) {
+		found, err := d.searchAll(c, containerID, filters)
+		if err != nil {
+			log.Error("could not search for objects", zap.Error(err))
+			response.Error(c, "could not search for objects: "+err.Error(), fasthttp.StatusBadRequest)
+			return
+		}
+		ids = mergeObjectIDs(ids, found)
+	}
+	if len(ids) == 0 {
+		log.Error("objects not found")
+	}
+
+	d.streamArchive(c, log, *containerID, format, ids, nil)
+}
+
+// archiveSearchFilters returns filters to search for objects which names in
+// the archive start with the prefix. Objects without FilePath attribute are
+// named by FileName and objects without both attributes are named by ID, so
+// they're matched by these values. Only regular objects are matched, so
+// tombstones, locks and storage groups aren't added to the archive.
+func archiveSearchFilters(prefix string) []object.SearchFilters {
+	newFilters := func() object.SearchFilters {
+		filters := object.NewSearchFilters()
+		filters.AddRootFilter()
+		filters.AddTypeFilter(object.MatchStringEqual, object.TypeRegular)
+		return filters
+	}
+
+	if prefix == "" {
+		return []object.SearchFilters{newFilters()}
+	}
+
+	byPath := newFilters()
+	byPath.AddFilter(object.AttributeFilePath, prefix, object.MatchCommonPrefix)
+
+	byName := newFilters()
+	byName.AddFilter(object.AttributeFilePath, "", object.MatchNotPresent)
+	byName.AddFilter(object.AttributeFileName, prefix, object.MatchCommonPrefix)
+
+	res := []object.SearchFilters{byPath, byName}
+
+	var id oid.ID
+	if err := id.DecodeString(prefix); err == nil {
+		byID := newFilters()
+		byID.AddFilter(object.AttributeFilePath, "", object.MatchNotPresent)
+		byID.AddFilter(object.AttributeFileName, "", object.MatchNotPresent)
+		byID.AddObjectIDFilter(object.MatchStringEqual, id)
+		res = append(res, byID)
+	}
+
+	return res
+}
+
+// prepareArchive checks the archive format (it can be overridden by the format
+// query parameter), the container and stores the bearer token. The last return
+// value is false if the response has been already written.
+func (d *Downloader) prepareArchive(c *fasthttp.RequestCtx, log *zap.Logger, scid, format string) (string, *cid.ID, bool) {
+	if f := c.QueryArgs().Peek(archiveFormatParam); len(f) != 0 {
+		format = string(f)
+	}
+	switch format {
+	case formatZip, formatTar, formatTarGz:
+	default:
+		log.Error("unsupported archive format", zap.String("format", format))
+		response.Error(c, "unsupported archive format: "+format, fasthttp.StatusBadRequest)
+		return "", nil, false
+	}
+
+	containerID, err := utils.GetContainerID(d.appCtx, scid, d.containerResolver)
+	if err != nil {
+		log.Error("wrong container id", zap.Error(err))
+		response.Error(c, "wrong container id", fasthttp.StatusBadRequest)
+		return "", nil, false
+	}
+
+	if err = tokens.StoreBearerToken(c); err != nil {
+		log.Error("could not fetch and store bearer token", zap.Error(err))
+		response.Error(c, "could not fetch and store bearer token: "+err.Error(), fasthttp.StatusBadRequest)
+		return "", nil, false
+	}
+
+	// check if container exists here to be able to return 404 error,
+	// otherwise we get this error only in object iteration step
+	// and client get 200 OK.
+	if _, err = d.getContainer(*containerID); err != nil {
+		log.Error("could not check container existence", zap.Error(err))
+		if client.IsErrContainerNotFound(err) {
+			response.Error(c, "Not Found", fasthttp.StatusNotFound)
+			return "", nil, false
+		}
+		response.Error(c, "could not check container existence: "+err.Error(), fasthttp.StatusBadRequest)
+		return "", nil, false
+	}
+
+	return format, containerID, true
+}
+
+type (
+	// archiveItem is an object to be written to the archive.
+	archiveItem struct {
+		addr   oid.Address
+		header *object.Object
+		name   string
+	}
+
+	archiveManifest struct {
+		ContainerID string                  `json:"container_id"`
+		Objects     []archiveManifestObject `json:"objects"`
+		Errors      []archiveManifestError  `json:"errors,omitempty"`
+	}
+
+	archiveManifestObject struct {
+		Path       string            `json:"path"`
+		ObjectID   string            `json:"object_id"`
+		Size       uint64            `json:"size"`
+		Attributes map[string]string `json:"attributes,omitempty"`
+	}
+
+	archiveManifestError struct {
+		ObjectID string `json:"object_id"`
+		Path     string `json:"path,omitempty"`
+		Error    string `json:"error"`
+	}
+)
+
+// streamArchive writes the objects to the archive sorted by their names in
+// the archive. Names are taken from the FilePath or FileName attribute or
+// object ID unless they're specified explicitly. Manifest with object
+// attributes is appended to the archive if it's requested by the manifest
+// query parameter. Objects which can't be added to the archive are handled
+// according to the error policy.
+//
+// Content-Length is set for zip archive in store mode, see archiveContentLength.
+// The archive is truncated then on any failure during streaming, because it
+// can't be completed with the declared size.
+func (d *Downloader) streamArchive(c *fasthttp.RequestCtx, log *zap.Logger, cnrID cid.ID, format string, ids []oid.ID, names map[oid.ID]string) {
+	btoken := bearerToken(c)
+	policy := d.settings.ArchiveErrorPolicy()
+	compress := d.settings.ZipCompression()
+	withManifest := c.QueryArgs().GetBool(archiveManifestParam)
+
+	items, failures := d.archiveItems(log, cnrID, ids, names, btoken)
+	if len(failures) != 0 && policy == ArchiveErrorsAbort {
+		// the response hasn't been started yet, so the client can get
+		// the error instead of truncated archive
+		response.Error(c, fmt.Sprintf("could not add object %s to archive: %v",
+			failures[0].id.EncodeToString(), failures[0].err), fasthttp.StatusBadRequest)
+		return
+	}
+
+	sortArchiveFailures(failures)
+
+	size := archiveContentLength(log, cnrID, format, compress, items, failures, policy, withManifest)
+
+	contentType, ext := archiveContentType(format)
+	c.Response.Header.Set(fasthttp.HeaderContentType, contentType)
+	c.Response.Header.Set(fasthttp.HeaderContentDisposition, "attachment; filename=\"archive."+ext+"\"")
+	c.Response.SetStatusCode(http.StatusOK)
+
+	c.Response.SetBodyStream(fasthttp.NewStreamReader(func(w *bufio.Writer) {
+		var (
+			buf      []byte
+			aborted  bool
+			start    = time.Now()
+			archive  = newArchiveWriter(format, compress, w)
+			fetcher  = d.newPrefetcher(btoken)
+			manifest = archiveManifest{ContainerID: cnrID.EncodeToString()}
+		)
+
+		go func() {
+			for _, item := range items {
+				fetcher.add(item)
+			}
+			fetcher.close()
+		}()
+
+		for entry := range fetcher.entries() {
+			fetcher.wait(entry)
+			if aborted {
+				fetcher.release(entry)
+				continue
+			}
+			if buf == nil {
+				buf = make([]byte, 3<<20) // the same as for upload
+			}
+
+			started, err := d.archiveObject(archive, fetcher, entry, buf)
+			fetcher.release(entry)
+			if err != nil {
+				log.Error("failed to add object to archive", zap.String("oid", entry.item.addr.Object().EncodeToString()), zap.Error(err))
+				d.metrics.AddArchiveFailure()
+
+				// the partially written entry can't be skipped, the
+				// archive is broken whatever the policy is, and the
+				// archive of the declared size can't be completed
+				if policy == ArchiveErrorsAbort || started || size >= 0 {
+					// remaining entries are drained to stop the prefetcher
+					aborted = true
+					fetcher.cancel()
+					continue
+				}
+
+				failures = append(failures, archiveFailure{id: entry.item.addr.Object(), name: entry.item.name, err: err})
+				continue
+			}
+
+			if withManifest {
+				manifest.Objects = append(manifest.Objects, newManifestObject(entry.item))
+			}
+		}
+
+		if aborted {
+			// the archive isn't closed, so the client gets it truncated
+			return
+		}
+
+		sortArchiveFailures(failures)
+
+		if len(failures) != 0 && policy == ArchiveErrorsReport {
+			if err := writeArchiveErrors(archive, failures, items); err != nil {
+				log.Error("failed to add errors to archive", zap.Error(err))
+			}
+		}
+
+		if withManifest {
+			manifest.Errors = newManifestErrors(failures)
+			if err := writeManifest(archive, manifest, items); err != nil {
+				log.Error("failed to add manifest to archive", zap.Error(err))
+			}
+		}
+
+		if err := archive.close(); err != nil {
+			log.Error("close archive writer", zap.Error(err))
+		}
+
+		d.metrics.ObserveArchiveDuration(time.Since(start))
+	}), size)
+}
+
+// archiveContentLength returns the size of the archive or -1 if it's unknown.
+// The size of zip archive in store mode is known in advance, failures before
+// streaming are accounted according to the policy.
+func archiveContentLength(log *zap.Logger, cnrID cid.ID, format string, compress bool, items []*archiveItem,
+	failures []archiveFailure, policy string, withManifest bool) int {
+	if format != formatZip || compress {
+		return -1
+	}
+
+	size, err := zipArchiveSize(cnrID, items, failures, policy, withManifest)
+	if err != nil {
+		log.Error("could not compute archive size", zap.Error(err))
+		return -1
+	}
+	if size > math.MaxInt {
+		return -1
+	}
+
+	return int(size)
+}
+
+// zipArchiveSize returns the size of zip archive in store mode if all the
+// objects are added to it successfully.
+func zipArchiveSize(cnrID cid.ID, items []*archiveItem, failures []archiveFailure, policy string, withManifest bool) (uint64, error) {
+	files, err := archiveFiles(cnrID, items, failures, policy, withManifest)
+	if err != nil {
+		return 0, err
+	}
+	return zipStoreSize(files)
+}
+
+// archiveFiles returns files of the archive if all the objects are added to it
+// successfully.
+func archiveFiles(cnrID cid.ID, items []*archiveItem, failures []archiveFailure, policy string, withManifest bool) ([]archiveFile, error) {
+	files := make([]archiveFile, 0, len(items)+2)
+	for _, item := range items {
+		modified, _ := lastModified(item.header)
+		files = append(files, archiveFile{
+			name:     item.name,
+			size:     item.header.PayloadSize(),
+			modified: modified,
+		})
+	}
+
+	modified := latestModified(items)
+
+	if len(failures) != 0 && policy == ArchiveErrorsReport {
+		files = append(files, archiveFile{
+			name:     archiveErrorsName,
+			size:     uint64(len(encodeArchiveErrors(failures))),
+			modified: modified,
+		})
+	}
+
+	if withManifest {
+		manifest := archiveManifest{
+			ContainerID: cnrID.EncodeToString(),
+			Errors:      newManifestErrors(failures),
+		}
+		for _, item := range items {
+			manifest.Objects = append(manifest.Objects, newManifestObject(item))
+		}
+
+		data, err := encodeManifest(manifest)
+		if err != nil {
+			return nil, err
+		}
+
+		files = append(files, archiveFile{
+			name:     archiveManifestName,
+			size:     uint64(len(data)),
+			modified: modified,
+		})
+	}
+
+	return files, nil
+}
+
+// archiveItems receives headers of the objects and returns them sorted by
+// the names in the archive (and by IDs for the same names). Objects with the
+// same names are renamed. Objects which can't be added to the archive are
+// returned separately.
+func (d *Downloader) archiveItems(log *zap.Logger, cnrID cid.ID, ids []oid.ID, names map[oid.ID]string, btoken *bearer.Token) ([]*archiveItem, []archiveFailure) {
+	headers, errs := d.headObjects(cnrID, ids, btoken)
+
+	var (
+		res      = make([]*archiveItem, 0, len(ids))
+		failures []archiveFailure
+	)
+	for i, header := range headers {
+		item := &archiveItem{header: header}
+		item.addr.SetContainer(cnrID)
+		item.addr.SetObject(ids[i])
+
+		if errs[i] == nil {
+			if name, ok := names[ids[i]]; ok {
+				item.name, errs[i] = name, checkArchiveFilePath(name)
+			} else {
+				item.name = archiveFileName(ids[i], header)
+			}
+		}
+
+		if errs[i] != nil {
+			log.Error("failed to add object to archive", zap.String("oid", ids[i].EncodeToString()), zap.Error(errs[i]))
+			d.metrics.AddArchiveFailure()
+			failures = append(failures, archiveFailure{id: ids[i], name: item.name, err: errs[i]})
+			continue
+		}
+		res = append(res, item)
+	}
+
+	sortArchiveItems(res)
+	if dedupeArchiveNames(res) {
+		sortArchiveItems(res)
+	}
+
+	return res, failures
+}
+
+func sortArchiveItems(items []*archiveItem) {
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].name != items[j].name {
+			return items[i].name < items[j].name
+		}
+		return items[i].addr.Object().EncodeToString() < items[j].addr.Object().EncodeToString()
+	})
+}
+
+// archiveObject adds the object to the archive. The first return value is
+// true if the archive entry has been started, see writeArchiveEntry.
+func (d *Downloader) archiveObject(archive archiveWriter, fetcher *prefetcher, entry *prefetchEntry, buf []byte) (bool, error) {
+	payload, err := fetcher.payloadReader(entry)
+	if err != nil {
+		return false, err
+	}
+
+	defer payload.Close()
+
+	started, err := writeArchiveEntry(archive, entry.item, payload, buf)
+	if err != nil {
+		return started, err
+	}
+
+	d.metrics.AddArchiveObject(entry.item.header.PayloadSize())
+
+	return true, nil
+}
+
+// writeArchiveEntry adds the file with the item payload to the archive. The
+// first return value is true if the entry header has been written. Its size
+// is already declared then, so the archive can't be continued on error.
+func writeArchiveEntry(archive archiveWriter, item *archiveItem, payload io.Reader, buf []byte) (bool, error) {
+	modified, _ := lastModified(item.header)
+
+	objWriter, err := archive.createFile(archiveFile{
+		name:     item.name,
+		size:     item.header.PayloadSize(),
+		modified: modified,
+	})
+	if err != nil {
+		return false, fmt.Errorf("archive create header: %v", err)
+	}
+
+	n, err := io.CopyBuffer(objWriter, payload, buf)
+	if err != nil {
+		return true, fmt.Errorf("copy object payload to archive file: %v", err)
+	}
+	if uint64(n) != item.header.PayloadSize() {
+		return true, fmt.Errorf("payload size mismatch: expected %d, got %d", item.header.PayloadSize(), n)
+	}
+
+	if err = archive.flush(); err != nil {
+		return true, fmt.Errorf("flush archive writer: %v", err)
+	}
+
+	return true, nil
+}
+
+func newManifestObject(item *archiveItem) archiveManifestObject {
+	res := archiveManifestObject{
+		Path:     item.name,
+		ObjectID: item.addr.Object().EncodeToString(),
+		Size:     item.header.PayloadSize(),
+	}
+
+	attrs := item.header.Attributes()
+	if len(attrs) != 0 {
+		res.Attributes = make(map[string]string, len(attrs))
+		for _, attr := range attrs {
+			res.Attributes[attr.Key()] = attr.Value()
+		}
+	}
+
+	return res
+}
+
+// writeManifest adds the manifest file to the archive. Its modification time
+// is the latest one of the objects to keep the archive deterministic.
+func writeManifest(archive archiveWriter, manifest archiveManifest, items []*archiveItem) error {
+	data, err := encodeManifest(manifest)
+	if err != nil {
+		return err
+	}
+
+	return writeArchiveFile(archive, archiveManifestName, data, latestModified(items))
+}
+
+func encodeManifest(manifest archiveManifest) ([]byte, error) {
+	data, err := json.MarshalIndent(manifest, "", "\t")
+	if err != nil {
+		return nil, fmt.Errorf("encode manifest: %w", err)
+	}
+	return data, nil
+}
+
+// latestModified returns the latest modification time of the objects.
+func latestModified(items []*archiveItem) time.Time {
+	var modified time.Time
+	for _, item := range items {
+		if t, ok := lastModified(item.header); ok && t.After(modified) {
+			modified = t
+		}
+	}
+	return modified
+}
+
+// writeArchiveFile adds the file with the data to the archive.
+func writeArchiveFile(archive archiveWriter, name string, data []byte, modified time.Time) error {
+	w, err := archive.createFile(archiveFile{
+		name:     name,
+		size:     uint64(len(data)),
+		modified: modified,
+	})
+	if err != nil {
+		return err
+	}
+
+	if _, err = w.Write(data); err != nil {
+		return err
+	}
+
+	return archive.flush()
+}
+
+// dedupeArchiveNames renames items with the same names in the archive, e.g.
+// the second 'file.txt' becomes 'file (1).txt'. Items must be sorted, so the
+// first of them keeps its name. It returns true if some item is renamed.
+func dedupeArchiveNames(items []*archiveItem) bool {
+	used := make(map[string]struct{}, len(items))
+	for _, item := range items {
+		used[item.name] = struct{}{}
+	}
+
+	var (
+		prev    string
+		renamed bool
+	)
+	for i, item := range items {
+		if i == 0 || item.name != prev {
+			prev = item.name
+			continue
+		}
+
+		ext := path.Ext(item.name)
+		base := strings.TrimSuffix(item.name, ext)
+		if base == "" || strings.HasSuffix(base, "/") {
+			// hidden file without extension
+			base, ext = item.name, ""
+		}
+
+		for n := 1; ; n++ {
+			name := fmt.Sprintf("%s (%d)%s", base, n, ext)
+			if _, ok := used[name]; !ok {
+				used[name] = struct{}{}
+				item.name = name
+				break
+			}
+		}
+		renamed = true
+	}
+
+	return renamed
+}
+
+// archiveFileName returns the name of the object in the archive. It's taken
+// from the FilePath or FileName attribute, the object ID is used if there are
+// no valid attributes.
+func archiveFileName(id oid.ID, obj *object.Object) string {
+	var filePath, fileName string
+	for _, attr := range obj.Attributes() {
+		switch attr.Key() {
+		case object.AttributeFilePath:
+			filePath = attr.Value()
+		case object.AttributeFileName:
+			fileName = attr.Value()
+		}
+	}
+
+	for _, name := range []string{filePath, fileName} {
+		if checkArchiveFilePath(name) == nil {
+			return name
+		}
+	}
+
+	return id.EncodeToString()
+}
+
+func checkArchiveFilePath(filePath string) error {
+	if len(filePath) == 0 || filePath[len(filePath)-1] == '/' {
+		return fmt.Errorf("invalid filepath '%s'", filePath)
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package downloader
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+)
+
+// Policies of handling objects which can't be added to the archive.
+const (
+	// ArchiveErrorsSkip skips such objects, failures are only logged.
+	ArchiveErrorsSkip = "skip"
+	// ArchiveErrorsAbort stops the archive streaming, so the client gets
+	// a truncated invalid archive.
+	ArchiveErrorsAbort = "abort"
+	// ArchiveErrorsReport skips such objects and lists them in the file
+	// appended to the archive.
+	ArchiveErrorsReport = "report"
+)
+
+// archiveErrorsName is a name of the file listing objects which haven't been
+// added to the archive.
+const archiveErrorsName = "ERRORS.txt"
+
+// archiveFailure describes the object which hasn't been added to the archive.
+// Name is empty if it's unknown.
+type archiveFailure struct {
+	id   oid.ID
+	name string
+	err  error
+}
+
+// ArchiveErrorPolicy returns the policy of handling objects which can't be
+// added to the archive.
+func (s *Settings) ArchiveErrorPolicy() string {
+	if policy := s.archiveErrorPolicy.Load(); policy != "" {
+		return policy
+	}
+	return ArchiveErrorsSkip
+}
+
+// SetArchiveErrorPolicy sets the policy of handling objects which can't be
+// added to the archive.
+func (s *Settings) SetArchiveErrorPolicy(val string) error {
+	switch val {
+	case ArchiveErrorsSkip, ArchiveErrorsAbort, ArchiveErrorsReport:
+		s.archiveErrorPolicy.Store(val)
+		return nil
+	default:
+		return fmt.Errorf("unknown archive error policy: %s", val)
+	}
+}
+
+func sortArchiveFailures(failures []archiveFailure) {
+	sort.Slice(failures, func(i, j int) bool {
+		return failures[i].id.EncodeToString() < failures[j].id.EncodeToString()
+	})
+}
+
+// writeArchiveErrors adds the file listing failed objects to the archive.
+// Every line contains tab-separated object ID, name in the archive and
+// the reason of the failure.
+func writeArchiveErrors(archive archiveWriter, failures []archiveFailure, items []*archiveItem) error {
+	return writeArchiveFile(archive, archiveErrorsName, encodeArchiveErrors(failures), latestModified(items))
+}
+
+func encodeArchiveErrors(failures []archiveFailure) []byte {
+	var buf bytes.Buffer
+	for _, f := range failures {
+		fmt.Fprintf(&buf, "%s\t%s\t%v\n", f.id.EncodeToString(), f.name, f.err)
+	}
+	return buf.Bytes()
+}
+
+func newManifestErrors(failures []archiveFailure) []archiveManifestError {
+	if len(failures) == 0 {
+		return nil
+	}
+
+	res := make([]archiveManifestError, len(failures))
+	for i, f := range failures {
+		res[i] = archiveManifestError{
+			ObjectID: f.id.EncodeToString(),
+			Path:     f.name,
+			Error:    f.err.Error(),
+		}
+	}
+
+	return res
+}
@@ -0,0 +1,167 @@
+package downloader
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/nspcc-dev/neofs-http-gw/response"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+)
+
+type (
+	// archiveListRequest is a body of request to download explicitly
+	// listed objects or objects matching the filters in the archive.
+	archiveListRequest struct {
+		Objects []archiveListObject `json:"objects"`
+		Filters []archiveListFilter `json:"filters"`
+	}
+
+	archiveListObject struct {
+		ObjectID string `json:"object_id"`
+		Name     string `json:"name,omitempty"`
+	}
+
+	archiveListFilter struct {
+		Key   string `json:"key"`
+		Match string `json:"match"`
+		Value string `json:"value,omitempty"`
+	}
+)
+
+// DownloadZippedList handles requests to download the listed objects in zip.
+func (d *Downloader) DownloadZippedList(c *fasthttp.RequestCtx) {
+	d.downloadArchiveList(c, formatZip)
+}
+
+// DownloadTarList handles requests to download the listed objects in tar.
+func (d *Downloader) DownloadTarList(c *fasthttp.RequestCtx) {
+	d.downloadArchiveList(c, formatTar)
+}
+
+func (d *Downloader) downloadArchiveList(c *fasthttp.RequestCtx, format string) {
+	scid, _ := c.UserValue("cid").(string)
+	log := d.log.With(zap.String("cid", scid))
+
+	var body io.Reader = c.RequestBodyStream()
+	if body == nil {
+		// request body isn't streamed, so it has been already read
+		body = bytes.NewReader(c.Request.Body())
+	}
+
+	var req archiveListRequest
+	if err := json.NewDecoder(body).Decode(&req); err != nil {
+		log.Error("could not decode request", zap.Error(err))
+		response.Error(c, "could not decode request: "+err.Error(), fasthttp.StatusBadRequest)
+		return
+	}
+
+	ids, names, err := req.objects()
+	if err != nil {
+		log.Error("invalid list of objects", zap.Error(err))
+		response.Error(c, "invalid list of objects: "+err.Error(), fasthttp.StatusBadRequest)
+		return
+	}
+
+	filters, err := req.searchFilters()
+	if err != nil {
+		log.Error("invalid search filters", zap.Error(err))
+		response.Error(c, "invalid search filters: "+err.Error(), fasthttp.StatusBadRequest)
+		return
+	}
+
+	format, containerID, ok := d.prepareArchive(c, log, scid, format)
+	if !ok {
+		return
+	}
+
+	if filters != nil {
+		found, err := d.searchAll(c, containerID, filters)
+		if err != nil {
+			log.Error("could not search for objects", zap.Error(err))
+			response.Error(c, "could not search for objects: "+err.Error(), fasthttp.StatusBadRequest)
+			return
+		}
+		ids = mergeObjectIDs(ids, found)
+	}
+
+	d.streamArchive(c, log, *containerID, format, ids, names)
+}
+
+// objects returns IDs of the explicitly listed objects and their names in
+// the archive if they're specified.
+func (r archiveListRequest) objects() ([]oid.ID, map[oid.ID]string, error) {
+	if len(r.Objects) == 0 && len(r.Filters) == 0 {
+		return nil, nil, errors.New("neither objects nor filters are specified")
+	}
+
+	ids := make([]oid.ID, 0, len(r.Objects))
+	names := make(map[oid.ID]string)
+
+	for _, obj := range r.Objects {
+		var id oid.ID
+		if err := id.DecodeString(obj.ObjectID); err != nil {
+			return nil, nil, fmt.Errorf("invalid object id '%s': %w", obj.ObjectID, err)
+		}
+
+		if obj.Name != "" {
+			if err := checkArchiveFilePath(obj.Name); err != nil {
+				return nil, nil, err
+			}
+			names[id] = obj.Name
+		}
+
+		ids = append(ids, id)
+	}
+
+	return mergeObjectIDs(nil, ids), names, nil
+}
+
+// searchFilters returns NeoFS search filters. It returns nil if there are
+// no filters in the request.
+func (r archiveListRequest) searchFilters() (object.SearchFilters, error) {
+	if len(r.Filters) == 0 {
+		return nil, nil
+	}
+
+	filters := object.NewSearchFilters()
+	filters.AddRootFilter()
+
+	for _, f := range r.Filters {
+		if f.Key == "" {
+			return nil, errors.New("empty filter key")
+		}
+
+		match, err := searchMatchType(f.Match)
+		if err != nil {
+			return nil, err
+		}
+
+		filters.AddFilter(f.Key, f.Value, match)
+	}
+
+	return filters, nil
+}
+
+// mergeObjectIDs appends IDs from src to dst skipping duplicates.
+func mergeObjectIDs(dst, src []oid.ID) []oid.ID {
+	seen := make(map[oid.ID]struct{}, len(dst)+len(src))
+	for _, id := range dst {
+		seen[id] = struct{}{}
+	}
+
+	for _, id := range src {
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		dst = append(dst, id)
+	}
+
+	return dst
+}
@@ -0,0 +1,54 @@
+package downloader
+
+import (
+	"testing"
+
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	oidtest "github.com/nspcc-dev/neofs-sdk-go/object/id/test"
+	"github.com/stretchr/testify/require"
+)
+
+func TestArchiveListRequest(t *testing.T) {
+	id1, id2 := oidtest.ID(), oidtest.ID()
+
+	req := archiveListRequest{
+		Objects: []archiveListObject{
+			{ObjectID: id1.EncodeToString(), Name: "renamed.txt"},
+			{ObjectID: id2.EncodeToString()},
+			{ObjectID: id1.EncodeToString(), Name: "renamed.txt"},
+		},
+		Filters: []archiveListFilter{
+			{Key: object.AttributeFilePath, Match: "prefix", Value: "dir/"},
+		},
+	}
+
+	ids, names, err := req.objects()
+	require.NoError(t, err)
+	require.Equal(t, []oid.ID{id1, id2}, ids)
+	require.Equal(t, map[oid.ID]string{id1: "renamed.txt"}, names)
+
+	filters, err := req.searchFilters()
+	require.NoError(t, err)
+	require.Len(t, filters, 2) // including root filter
+	require.Equal(t, object.MatchCommonPrefix, filters[1].Operation())
+
+	t.Run("invalid", func(t *testing.T) {
+		_, _, err := archiveListRequest{}.objects()
+		require.Error(t, err)
+
+		_, _, err = archiveListRequest{Objects: []archiveListObject{{ObjectID: "invalid"}}}.objects()
+		require.Error(t, err)
+
+		_, _, err = archiveListRequest{Objects: []archiveListObject{{ObjectID: id1.EncodeToString(), Name: "dir/"}}}.objects()
+		require.Error(t, err)
+
+		_, err = archiveListRequest{Filters: []archiveListFilter{{Key: "FileName", Match: "like"}}}.searchFilters()
+		require.Error(t, err)
+	})
+}
+
+func TestMergeObjectIDs(t *testing.T) {
+	id1, id2, id3 := oidtest.ID(), oidtest.ID(), oidtest.ID()
+	require.Equal(t, []oid.ID{id1, id2, id3}, mergeObjectIDs([]oid.ID{id1, id2}, []oid.ID{id2, id3, id1}))
+}
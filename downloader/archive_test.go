@@ -0,0 +1,367 @@
+package downloader
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	v2object "github.com/nspcc-dev/neofs-api-go/v2/object"
+	cidtest "github.com/nspcc-dev/neofs-sdk-go/container/id/test"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	oidtest "github.com/nspcc-dev/neofs-sdk-go/object/id/test"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func newArchiveTestObject(filePath string, payload []byte, modified time.Time) *object.Object {
+	attrPath := object.NewAttribute()
+	attrPath.SetKey(object.AttributeFilePath)
+	attrPath.SetValue(filePath)
+
+	attrTimestamp := object.NewAttribute()
+	attrTimestamp.SetKey(object.AttributeTimestamp)
+	attrTimestamp.SetValue(strconv.FormatInt(modified.Unix(), 10))
+
+	obj := object.New()
+	obj.SetAttributes(*attrPath, *attrTimestamp)
+	obj.SetPayloadSize(uint64(len(payload)))
+
+	return obj
+}
+
+func TestTarArchive(t *testing.T) {
+	payload := []byte("content")
+	modified := time.Now().Add(-time.Hour).Truncate(time.Second)
+	file := archiveFile{name: "dir/file.txt", size: uint64(len(payload)), modified: modified}
+
+	for _, format := range []string{formatTar, formatTarGz} {
+		t.Run(format, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+
+			archive := newArchiveWriter(format, false, buf)
+			w, err := archive.createFile(file)
+			require.NoError(t, err)
+			_, err = w.Write(payload)
+			require.NoError(t, err)
+			require.NoError(t, archive.flush())
+			require.NoError(t, archive.close())
+
+			var r io.Reader = buf
+			if format == formatTarGz {
+				r, err = gzip.NewReader(buf)
+				require.NoError(t, err)
+			}
+
+			tr := tar.NewReader(r)
+			hdr, err := tr.Next()
+			require.NoError(t, err)
+			require.Equal(t, "dir/file.txt", hdr.Name)
+			require.EqualValues(t, len(payload), hdr.Size)
+			require.EqualValues(t, archiveFileMode, hdr.Mode)
+			require.True(t, modified.Equal(hdr.ModTime))
+
+			data, err := io.ReadAll(tr)
+			require.NoError(t, err)
+			require.Equal(t, payload, data)
+
+			_, err = tr.Next()
+			require.ErrorIs(t, err, io.EOF)
+		})
+	}
+}
+
+func TestWriteArchiveEntry(t *testing.T) {
+	payload := []byte("content")
+	item := &archiveItem{header: newArchiveTestObject("file.txt", payload, time.Now()), name: "file.txt"}
+	buf := make([]byte, 2)
+
+	for _, format := range []string{formatZip, formatTar, formatTarGz} {
+		t.Run(format, func(t *testing.T) {
+			archive := newArchiveWriter(format, false, io.Discard)
+
+			started, err := writeArchiveEntry(archive, item, bytes.NewReader(payload), buf)
+			require.NoError(t, err)
+			require.True(t, started)
+
+			// payload fails halfway after the header is written
+			broken := io.MultiReader(bytes.NewReader(payload[:3]), iotest.ErrReader(errors.New("stream is broken")))
+			started, err = writeArchiveEntry(archive, item, broken, buf)
+			require.Error(t, err)
+			require.True(t, started)
+
+			started, err = writeArchiveEntry(newArchiveWriter(format, false, io.Discard), item, bytes.NewReader(payload[:3]), buf)
+			require.Error(t, err)
+			require.True(t, started)
+		})
+	}
+
+	t.Run("tar can't be continued", func(t *testing.T) {
+		archive := newArchiveWriter(formatTar, false, io.Discard)
+
+		started, err := writeArchiveEntry(archive, item, bytes.NewReader(payload[:3]), buf)
+		require.Error(t, err)
+		require.True(t, started)
+
+		_, err = archive.createFile(archiveFile{name: "next.txt"})
+		require.Error(t, err)
+	})
+}
+
+func TestArchiveFileName(t *testing.T) {
+	id := oidtest.ID()
+
+	name := archiveFileName(id, newArchiveTestObject("dir/file", nil, time.Now()))
+	require.Equal(t, "dir/file", name)
+
+	name = archiveFileName(id, object.New())
+	require.Equal(t, id.EncodeToString(), name)
+
+	attr := object.NewAttribute()
+	attr.SetKey(object.AttributeFileName)
+	attr.SetValue("file.txt")
+
+	obj := newArchiveTestObject("dir/", nil, time.Now())
+	obj.SetAttributes(append(obj.Attributes(), *attr)...)
+	require.Equal(t, "file.txt", archiveFileName(id, obj))
+}
+
+func TestDedupeArchiveNames(t *testing.T) {
+	names := []string{"dir/.env", "dir/.env", "file (1).txt", "file.txt", "file.txt", "file.txt", "noext", "noext"}
+
+	items := make([]*archiveItem, len(names))
+	for i := range names {
+		items[i] = &archiveItem{name: names[i]}
+	}
+
+	require.True(t, dedupeArchiveNames(items))
+
+	res := make([]string, len(items))
+	for i := range items {
+		res[i] = items[i].name
+	}
+	require.Equal(t, []string{"dir/.env", "dir/.env (1)", "file (1).txt", "file.txt", "file (2).txt", "file (3).txt", "noext", "noext (1)"}, res)
+
+	require.False(t, dedupeArchiveNames(items[:1]))
+}
+
+func TestArchiveSearchFilters(t *testing.T) {
+	require.Len(t, archiveSearchFilters(""), 1)
+	require.Len(t, archiveSearchFilters("dir/"), 2)
+	require.Len(t, archiveSearchFilters(oidtest.ID().EncodeToString()), 3)
+
+	for _, filters := range archiveSearchFilters(oidtest.ID().EncodeToString()) {
+		var regular bool
+		for _, filter := range filters {
+			if filter.Header() == v2object.FilterHeaderObjectType {
+				regular = filter.Value() == object.TypeRegular.String()
+			}
+		}
+		require.True(t, regular)
+	}
+}
+
+func TestMemoryBudget(t *testing.T) {
+	budget := newMemoryBudget(10)
+
+	require.True(t, budget.tryAcquire(6))
+	require.False(t, budget.tryAcquire(5))
+	require.True(t, budget.tryAcquire(4))
+	require.False(t, budget.tryAcquire(1))
+	require.True(t, budget.tryAcquire(0))
+
+	budget.release(6)
+	require.True(t, budget.tryAcquire(5))
+
+	require.False(t, newMemoryBudget(0).tryAcquire(1))
+}
+
+func TestSortArchiveItems(t *testing.T) {
+	newItem := func(name string) *archiveItem {
+		item := &archiveItem{name: name}
+		item.addr.SetContainer(cidtest.ID())
+		item.addr.SetObject(oidtest.ID())
+		return item
+	}
+
+	items := []*archiveItem{newItem("b"), newItem("a/c"), newItem("a"), newItem("a/c")}
+	sortArchiveItems(items)
+
+	require.Equal(t, "a", items[0].name)
+	require.Equal(t, "a/c", items[1].name)
+	require.Equal(t, "a/c", items[2].name)
+	require.Equal(t, "b", items[3].name)
+	require.Less(t, items[1].addr.Object().EncodeToString(), items[2].addr.Object().EncodeToString())
+}
+
+func TestZipArchiveDeterministic(t *testing.T) {
+	modified := time.Unix(1670000000, 0)
+
+	build := func() []byte {
+		buf := new(bytes.Buffer)
+		archive := newArchiveWriter(formatZip, false, buf)
+
+		for _, name := range []string{"a.txt", "b.txt"} {
+			w, err := archive.createFile(archiveFile{name: name, size: 4, modified: modified})
+			require.NoError(t, err)
+			_, err = w.Write([]byte("data"))
+			require.NoError(t, err)
+		}
+		require.NoError(t, archive.close())
+
+		return buf.Bytes()
+	}
+
+	data := build()
+	require.Equal(t, data, build())
+
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+	require.Len(t, zr.File, 2)
+	require.True(t, modified.Equal(zr.File[0].Modified))
+}
+
+func TestWriteManifest(t *testing.T) {
+	modified := time.Now().Truncate(time.Second)
+	obj := newArchiveTestObject("dir/file.txt", []byte("content"), modified)
+
+	item := &archiveItem{header: obj, name: "dir/file.txt"}
+	item.addr.SetContainer(cidtest.ID())
+	item.addr.SetObject(oidtest.ID())
+
+	manifest := archiveManifest{
+		ContainerID: item.addr.Container().EncodeToString(),
+		Objects:     []archiveManifestObject{newManifestObject(item)},
+	}
+
+	buf := new(bytes.Buffer)
+	archive := newArchiveWriter(formatTar, false, buf)
+	require.NoError(t, writeManifest(archive, manifest, []*archiveItem{item}))
+	require.NoError(t, archive.close())
+
+	tr := tar.NewReader(buf)
+	hdr, err := tr.Next()
+	require.NoError(t, err)
+	require.Equal(t, archiveManifestName, hdr.Name)
+	require.True(t, modified.Equal(hdr.ModTime))
+
+	var res archiveManifest
+	require.NoError(t, json.NewDecoder(tr).Decode(&res))
+	require.Equal(t, manifest, res)
+	require.Equal(t, "dir/file.txt", res.Objects[0].Attributes[object.AttributeFilePath])
+}
+
+func TestWriteArchiveErrors(t *testing.T) {
+	id1, id2 := oidtest.ID(), oidtest.ID()
+	failures := []archiveFailure{
+		{id: id1, name: "dir/file.txt", err: errors.New("get NeoFS object: access denied")},
+		{id: id2, err: errors.New("invalid filepath ''")},
+	}
+	sortArchiveFailures(failures)
+
+	buf := new(bytes.Buffer)
+	archive := newArchiveWriter(formatTar, false, buf)
+	require.NoError(t, writeArchiveErrors(archive, failures, nil))
+	require.NoError(t, archive.close())
+
+	tr := tar.NewReader(buf)
+	hdr, err := tr.Next()
+	require.NoError(t, err)
+	require.Equal(t, archiveErrorsName, hdr.Name)
+
+	data, err := io.ReadAll(tr)
+	require.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	require.Len(t, lines, 2)
+	for i, f := range failures {
+		require.Equal(t, f.id.EncodeToString()+"\t"+f.name+"\t"+f.err.Error(), lines[i])
+	}
+
+	manifestErrors := newManifestErrors(failures)
+	require.Len(t, manifestErrors, 2)
+	require.Equal(t, failures[0].id.EncodeToString(), manifestErrors[0].ObjectID)
+	require.Nil(t, newManifestErrors(nil))
+}
+
+func TestArchiveErrorPolicy(t *testing.T) {
+	var s Settings
+	require.Equal(t, ArchiveErrorsSkip, s.ArchiveErrorPolicy())
+
+	require.NoError(t, s.SetArchiveErrorPolicy(ArchiveErrorsReport))
+	require.Error(t, s.SetArchiveErrorPolicy("ignore"))
+	require.Equal(t, ArchiveErrorsReport, s.ArchiveErrorPolicy())
+}
+
+func TestZipArchiveSize(t *testing.T) {
+	payload := []byte("content")
+	item := &archiveItem{header: newArchiveTestObject("dir/file.txt", payload, time.Now()), name: "dir/file.txt"}
+	item.addr.SetContainer(cidtest.ID())
+	item.addr.SetObject(oidtest.ID())
+	items := []*archiveItem{item}
+
+	failures := []archiveFailure{{id: oidtest.ID(), err: errors.New("head NeoFS object: not found")}}
+
+	for _, withManifest := range []bool{false, true} {
+		buf := new(bytes.Buffer)
+		archive := newArchiveWriter(formatZip, false, buf)
+
+		modified, _ := lastModified(item.header)
+		w, err := archive.createFile(archiveFile{name: item.name, size: uint64(len(payload)), modified: modified})
+		require.NoError(t, err)
+		_, err = w.Write(payload)
+		require.NoError(t, err)
+
+		require.NoError(t, writeArchiveErrors(archive, failures, items))
+		if withManifest {
+			manifest := archiveManifest{
+				ContainerID: item.addr.Container().EncodeToString(),
+				Objects:     []archiveManifestObject{newManifestObject(item)},
+				Errors:      newManifestErrors(failures),
+			}
+			require.NoError(t, writeManifest(archive, manifest, items))
+		}
+		require.NoError(t, archive.close())
+
+		size, err := zipArchiveSize(item.addr.Container(), items, failures, ArchiveErrorsReport, withManifest)
+		require.NoError(t, err)
+		require.EqualValues(t, buf.Len(), size)
+	}
+}
+
+func TestArchiveContentLength(t *testing.T) {
+	log := zap.NewNop()
+	cnrID := cidtest.ID()
+
+	payload := []byte("content")
+	items := make([]*archiveItem, 2)
+	for i, name := range []string{"a.txt", "b.txt"} {
+		items[i] = &archiveItem{header: newArchiveTestObject(name, payload, time.Now()), name: name}
+		items[i].addr.SetContainer(cnrID)
+		items[i].addr.SetObject(oidtest.ID())
+	}
+
+	expected, err := zipArchiveSize(cnrID, items, nil, ArchiveErrorsAbort, false)
+	require.NoError(t, err)
+	require.EqualValues(t, expected, archiveContentLength(log, cnrID, formatZip, false, items, nil, ArchiveErrorsAbort, false))
+
+	require.Equal(t, -1, archiveContentLength(log, cnrID, formatZip, true, items, nil, ArchiveErrorsAbort, false))
+	require.Equal(t, -1, archiveContentLength(log, cnrID, formatTar, false, items, nil, ArchiveErrorsAbort, false))
+
+	// failures before streaming are accounted according to the policy
+	failures := []archiveFailure{{id: oidtest.ID(), err: errors.New("head NeoFS object: not found")}}
+	require.EqualValues(t, expected, archiveContentLength(log, cnrID, formatZip, false, items, failures, ArchiveErrorsSkip, false))
+
+	withErrors, err := zipArchiveSize(cnrID, items, failures, ArchiveErrorsReport, false)
+	require.NoError(t, err)
+	require.Greater(t, withErrors, expected)
+	require.EqualValues(t, withErrors, archiveContentLength(log, cnrID, formatZip, false, items, failures, ArchiveErrorsReport, false))
+}
@@ -0,0 +1,180 @@
+package downloader
+
+import (
+	"archive/zip"
+	"hash"
+	"hash/crc32"
+	"io"
+	"time"
+	"unicode/utf8"
+)
+
+// Values of zip headers written by the standard library, see archive/zip.
+const (
+	zipVersion20          = 20
+	zipFlagDataDescriptor = 0x8
+	zipFlagUTF8           = 0x800
+	zipExtTimeExtraID     = 0x5455
+)
+
+// zipArchive writes files to zip archive. Files are written raw in store mode,
+// so their headers don't depend on the payload and the size of the archive
+// can be computed in advance (see zipStoreSize).
+type zipArchive struct {
+	w        *zip.Writer
+	compress bool
+
+	// header of the last file in store mode, its checksum is set when
+	// the next file is created or the archive is closed
+	header *zip.FileHeader
+	crc    hash.Hash32
+}
+
+func newZipArchive(w io.Writer, compress bool) *zipArchive {
+	return &zipArchive{
+		w:        zip.NewWriter(w),
+		compress: compress,
+		crc:      crc32.NewIEEE(),
+	}
+}
+
+func (a *zipArchive) createFile(f archiveFile) (io.Writer, error) {
+	if a.compress {
+		hdr := &zip.FileHeader{
+			Name:   f.name,
+			Method: zip.Deflate,
+		}
+		if !f.modified.IsZero() {
+			hdr.Modified = f.modified.UTC()
+		}
+
+		return a.w.CreateHeader(hdr)
+	}
+
+	a.finishFile()
+
+	hdr := newZipStoreHeader(f)
+	w, err := a.w.CreateRaw(hdr)
+	if err != nil {
+		return nil, err
+	}
+
+	a.header = hdr
+	a.crc.Reset()
+
+	return io.MultiWriter(w, a.crc), nil
+}
+
+// finishFile sets the checksum of the last file written in store mode. It
+// must be done before the zip writer writes the data descriptor of the file.
+func (a *zipArchive) finishFile() {
+	if a.header != nil {
+		a.header.CRC32 = a.crc.Sum32()
+		a.header = nil
+	}
+}
+
+func (a *zipArchive) flush() error {
+	return a.w.Flush()
+}
+
+func (a *zipArchive) close() error {
+	a.finishFile()
+	return a.w.Close()
+}
+
+// newZipStoreHeader returns the header of the file written raw in store mode.
+// It's the same as zip.Writer.CreateHeader makes except the sizes, which are
+// known in advance, and the checksum, which is set after the file is written.
+func newZipStoreHeader(f archiveFile) *zip.FileHeader {
+	hdr := &zip.FileHeader{
+		Name:               f.name,
+		Method:             zip.Store,
+		Flags:              zipFlagDataDescriptor,
+		CreatorVersion:     zipVersion20,
+		ReaderVersion:      zipVersion20,
+		CompressedSize64:   f.size,
+		UncompressedSize64: f.size,
+	}
+
+	if !isASCII(f.name) && utf8.ValidString(f.name) {
+		hdr.Flags |= zipFlagUTF8
+	}
+
+	if !f.modified.IsZero() {
+		modified := f.modified.UTC()
+		hdr.ModifiedDate, hdr.ModifiedTime = timeToMsDosTime(modified)
+
+		// extended timestamp with modification time only
+		mt := uint32(modified.Unix())
+		hdr.Extra = []byte{
+			zipExtTimeExtraID & 0xff, zipExtTimeExtraID >> 8,
+			5, 0, // size of the data
+			1, // flags: modification time is present
+			byte(mt), byte(mt >> 8), byte(mt >> 16), byte(mt >> 24),
+		}
+	}
+
+	return hdr
+}
+
+// zipStoreSize returns the size of zip archive in store mode with the files.
+// The archive with zero payloads is written to compute it, checksums
+// are omitted because they don't change the size.
+func zipStoreSize(files []archiveFile) (uint64, error) {
+	var (
+		cw    countWriter
+		zeros = make([]byte, 64<<10)
+		zw    = zip.NewWriter(&cw)
+	)
+
+	for _, f := range files {
+		w, err := zw.CreateRaw(newZipStoreHeader(f))
+		if err != nil {
+			return 0, err
+		}
+
+		for left := f.size; left > 0; {
+			chunk := zeros
+			if left < uint64(len(chunk)) {
+				chunk = chunk[:left]
+			}
+			if _, err = w.Write(chunk); err != nil {
+				return 0, err
+			}
+			left -= uint64(len(chunk))
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		return 0, err
+	}
+
+	return cw.n, nil
+}
+
+// countWriter discards data and counts the number of written bytes.
+type countWriter struct {
+	n uint64
+}
+
+func (w *countWriter) Write(p []byte) (int, error) {
+	w.n += uint64(len(p))
+	return len(p), nil
+}
+
+// timeToMsDosTime is the same as the one from archive/zip.
+func timeToMsDosTime(t time.Time) (uint16, uint16) {
+	date := uint16(t.Day() + int(t.Month())<<5 + (t.Year()-1980)<<9)
+	tm := uint16(t.Second()/2 + t.Minute()<<5 + t.Hour()<<11)
+	return date, tm
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
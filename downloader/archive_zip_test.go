@@ -0,0 +1,152 @@
+package downloader
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/rand"
+	"io"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestZipStoreSize(t *testing.T) {
+	modified := time.Unix(1670000000, 0)
+	files := []archiveFile{
+		{name: "a.txt", size: 10, modified: modified},
+		{name: "dir/empty"},
+		{name: "dir/файл.txt", size: 1000, modified: modified},
+		{name: "manifest.json", size: 100 << 10},
+	}
+
+	buf := new(bytes.Buffer)
+	archive := newZipArchive(buf, false)
+
+	payloads := make([][]byte, len(files))
+	for i, f := range files {
+		payloads[i] = make([]byte, f.size)
+		_, _ = rand.Read(payloads[i])
+
+		w, err := archive.createFile(f)
+		require.NoError(t, err)
+		_, err = w.Write(payloads[i])
+		require.NoError(t, err)
+		require.NoError(t, archive.flush())
+	}
+	require.NoError(t, archive.close())
+
+	size, err := zipStoreSize(files)
+	require.NoError(t, err)
+	require.EqualValues(t, buf.Len(), size)
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+	require.Len(t, zr.File, len(files))
+
+	for i, zf := range zr.File {
+		require.Equal(t, files[i].name, zf.Name)
+		require.Equal(t, zip.Store, zf.Method)
+		if !files[i].modified.IsZero() {
+			require.True(t, files[i].modified.Equal(zf.Modified))
+		}
+
+		r, err := zf.Open()
+		require.NoError(t, err)
+		data, err := io.ReadAll(r) // checksum is verified at EOF
+		require.NoError(t, err)
+		require.Equal(t, payloads[i], data)
+	}
+}
+
+func TestZipStoreSizeZip64(t *testing.T) {
+	if testing.Short() {
+		t.Skip("writes more than 4 GiB")
+	}
+
+	files := []archiveFile{
+		{name: "large", size: 1<<32 + 1},
+		{name: "small", size: 5},
+	}
+
+	w := new(sparseWriter)
+	archive := newZipArchive(w, false)
+
+	zeros := make([]byte, 1<<20)
+	for _, f := range files {
+		fw, err := archive.createFile(f)
+		require.NoError(t, err)
+
+		for left := f.size; left > 0; {
+			chunk := zeros
+			if left < uint64(len(chunk)) {
+				chunk = chunk[:left]
+			}
+			_, err = fw.Write(chunk)
+			require.NoError(t, err)
+			left -= uint64(len(chunk))
+		}
+	}
+	require.NoError(t, archive.close())
+
+	size, err := zipStoreSize(files)
+	require.NoError(t, err)
+	require.EqualValues(t, w.size, size)
+
+	zr, err := zip.NewReader(w, w.size)
+	require.NoError(t, err)
+	require.Len(t, zr.File, 2)
+	require.EqualValues(t, files[0].size, zr.File[0].UncompressedSize64)
+
+	r, err := zr.File[1].Open()
+	require.NoError(t, err)
+	data, err := io.ReadAll(r)
+	require.NoError(t, err)
+	require.Equal(t, make([]byte, 5), data)
+}
+
+// sparseWriter keeps only small writes, large ones are treated as zeros.
+// It allows to check archives of several GiB with zero payloads.
+type sparseWriter struct {
+	size   int64
+	chunks []sparseChunk
+}
+
+type sparseChunk struct {
+	off  int64
+	data []byte
+}
+
+func (w *sparseWriter) Write(p []byte) (int, error) {
+	if len(p) <= 64<<10 {
+		w.chunks = append(w.chunks, sparseChunk{off: w.size, data: append([]byte(nil), p...)})
+	}
+	w.size += int64(len(p))
+	return len(p), nil
+}
+
+func (w *sparseWriter) ReadAt(p []byte, off int64) (int, error) {
+	for i := range p {
+		p[i] = 0
+	}
+
+	end := off + int64(len(p))
+	for _, c := range w.chunks {
+		start, stop := c.off, c.off+int64(len(c.data))
+		if start < off {
+			start = off
+		}
+		if stop > end {
+			stop = end
+		}
+		if start < stop {
+			copy(p[start-off:stop-off], c.data[start-c.off:stop-c.off])
+		}
+	}
+
+	if end > w.size {
+		return int(w.size - off), io.EOF
+	}
+	return len(p), nil
+}
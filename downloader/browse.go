@@ -0,0 +1,337 @@
+package downloader
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nspcc-dev/neofs-http-gw/response"
+	"github.com/nspcc-dev/neofs-http-gw/tokens"
+	"github.com/nspcc-dev/neofs-http-gw/utils"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+)
+
+// maxBrowseEntries is the default number of directories and files in the
+// listing page.
+const maxBrowseEntries = 1000
+
+type (
+	browseResponse struct {
+		ContainerID string            `json:"container_id"`
+		Prefix      string            `json:"prefix"`
+		Directories []browseDirectory `json:"directories"`
+		Files       []browseFile      `json:"files"`
+		Offset      int               `json:"offset"`
+		Limit       int               `json:"limit"`
+		NextOffset  *int              `json:"next_offset,omitempty"`
+		Truncated   bool              `json:"truncated,omitempty"`
+	}
+
+	// browseDirectory is a virtual directory, i.e. common part of FilePath
+	// attributes of the objects.
+	browseDirectory struct {
+		Name    string `json:"name"`
+		Path    string `json:"path"`
+		Objects int    `json:"objects"`
+		Size    uint64 `json:"size"`
+	}
+
+	browseFile struct {
+		Name     string     `json:"name"`
+		Path     string     `json:"path"`
+		ObjectID string     `json:"object_id"`
+		Size     uint64     `json:"size"`
+		Modified *time.Time `json:"modified,omitempty"`
+	}
+)
+
+// Browse handles requests to list objects by FilePath prefix as directories
+// and files. The listing is rendered in HTML unless JSON is accepted.
+func (d *Downloader) Browse(c *fasthttp.RequestCtx) {
+	var (
+		scid, _   = c.UserValue("cid").(string)
+		prefix, _ = url.QueryUnescape(c.UserValue("prefix").(string))
+		log       = d.log.With(zap.String("cid", scid), zap.String("prefix", prefix))
+	)
+
+	if prefix != "" && !isDirectoryPath(prefix) {
+		prefix += "/"
+	}
+
+	containerID, err := utils.GetContainerID(d.appCtx, scid, d.containerResolver)
+	if err != nil {
+		log.Error("wrong container id", zap.Error(err))
+		response.Error(c, "wrong container id", fasthttp.StatusBadRequest)
+		return
+	}
+
+	offset, limit, err := parsePagination(c.QueryArgs(), maxBrowseEntries)
+	if err != nil {
+		log.Error("invalid pagination parameters", zap.Error(err))
+		response.Error(c, err.Error(), fasthttp.StatusBadRequest)
+		return
+	}
+
+	if err = tokens.StoreBearerToken(c); err != nil {
+		log.Error("could not fetch and store bearer token", zap.Error(err))
+		response.Error(c, "could not fetch and store bearer token: "+err.Error(), fasthttp.StatusBadRequest)
+		return
+	}
+
+	// check if container exists here to be able to return 404 error
+	if _, err = d.getContainer(*containerID); err != nil {
+		log.Error("could not check container existence", zap.Error(err))
+		d.handleContainerErr(c, err)
+		return
+	}
+
+	filters := object.NewSearchFilters()
+	filters.AddRootFilter()
+	filters.AddFilter(object.AttributeFilePath, prefix, object.MatchCommonPrefix)
+
+	// the objects are grouped into directories before paging, so all of
+	// them are received to keep directories complete on every page
+	ids, err := d.searchAll(c, containerID, filters)
+	if err != nil {
+		log.Error("could not search for objects", zap.Error(err))
+		response.Error(c, "could not search for objects: "+err.Error(), fasthttp.StatusBadRequest)
+		return
+	}
+
+	headers, errs := d.headObjects(*containerID, ids, bearerToken(c))
+	for i := range errs {
+		if errs[i] != nil {
+			log.Warn("could not head object", zap.String("oid", ids[i].EncodeToString()), zap.Error(errs[i]))
+			headers[i] = nil
+		}
+	}
+
+	resp := newBrowseResponse(prefix, ids, headers)
+	resp.ContainerID = containerID.EncodeToString()
+	resp.Offset = offset
+	resp.Limit = limit
+	if resp.paginate(offset, limit) {
+		next := offset + limit
+		resp.NextOffset = &next
+		resp.Truncated = true
+	}
+
+	if acceptsJSON(string(c.Request.Header.Peek(fasthttp.HeaderAccept))) {
+		c.SetContentType("application/json")
+		enc := json.NewEncoder(c)
+		enc.SetIndent("", "\t")
+		if err = enc.Encode(resp); err != nil {
+			log.Error("could not encode response", zap.Error(err))
+			response.Error(c, "could not encode response", fasthttp.StatusInternalServerError)
+		}
+		return
+	}
+
+	// links use the container as it's requested, e.g. by NNS name
+	var buf bytes.Buffer
+	if err = browseTemplate.Execute(&buf, browsePage{browseResponse: resp, Container: scid}); err != nil {
+		log.Error("could not render listing", zap.Error(err))
+		response.Error(c, "could not render listing", fasthttp.StatusInternalServerError)
+		return
+	}
+
+	c.SetContentType("text/html; charset=utf-8")
+	c.SetBody(buf.Bytes())
+}
+
+// newBrowseResponse groups the objects by the next part of FilePath after
+// the prefix. Objects without header are skipped.
+func newBrowseResponse(prefix string, ids []oid.ID, headers []*object.Object) browseResponse {
+	resp := browseResponse{
+		Prefix:      prefix,
+		Directories: []browseDirectory{},
+		Files:       []browseFile{},
+	}
+
+	dirs := make(map[string]int)
+
+	for i, header := range headers {
+		if header == nil {
+			continue
+		}
+
+		var filePath string
+		for _, attr := range header.Attributes() {
+			if attr.Key() == object.AttributeFilePath {
+				filePath = attr.Value()
+				break
+			}
+		}
+
+		name := strings.TrimPrefix(filePath, prefix)
+		if name == "" || (name == filePath && prefix != "") {
+			continue
+		}
+
+		if ind := strings.IndexByte(name, '/'); ind >= 0 {
+			name = name[:ind+1]
+			j, ok := dirs[name]
+			if !ok {
+				j = len(resp.Directories)
+				dirs[name] = j
+				resp.Directories = append(resp.Directories, browseDirectory{Name: name, Path: prefix + name})
+			}
+			resp.Directories[j].Objects++
+			resp.Directories[j].Size += header.PayloadSize()
+			continue
+		}
+
+		file := browseFile{
+			Name:     name,
+			Path:     filePath,
+			ObjectID: ids[i].EncodeToString(),
+			Size:     header.PayloadSize(),
+		}
+		if modified, ok := lastModified(header); ok {
+			modified = modified.UTC()
+			file.Modified = &modified
+		}
+		resp.Files = append(resp.Files, file)
+	}
+
+	sort.Slice(resp.Directories, func(i, j int) bool {
+		return resp.Directories[i].Name < resp.Directories[j].Name
+	})
+	sort.Slice(resp.Files, func(i, j int) bool {
+		if resp.Files[i].Name != resp.Files[j].Name {
+			return resp.Files[i].Name < resp.Files[j].Name
+		}
+		return resp.Files[i].ObjectID < resp.Files[j].ObjectID
+	})
+
+	return resp
+}
+
+// paginate leaves the requested page of the listing entries, directories are
+// listed before files. It returns true if there are more entries after the page.
+func (r *browseResponse) paginate(offset, limit int) bool {
+	var (
+		dirs  = len(r.Directories)
+		total = dirs + len(r.Files)
+		start = offset
+		end   = offset + limit
+	)
+	if end < start || end > total {
+		end = total
+	}
+	if start > end {
+		start = end
+	}
+
+	dirStart, dirEnd := start, end
+	if dirStart > dirs {
+		dirStart = dirs
+	}
+	if dirEnd > dirs {
+		dirEnd = dirs
+	}
+
+	r.Directories = r.Directories[dirStart:dirEnd]
+	r.Files = r.Files[start-dirStart : end-dirEnd]
+
+	return end < total
+}
+
+// acceptsJSON checks if JSON is preferred to HTML by the Accept header value.
+// Media types are checked in the order they're listed, quality values are
+// ignored.
+func acceptsJSON(accept string) bool {
+	for _, mediaRange := range strings.Split(accept, ",") {
+		if ind := strings.IndexByte(mediaRange, ';'); ind >= 0 {
+			mediaRange = mediaRange[:ind]
+		}
+		switch strings.TrimSpace(mediaRange) {
+		case "application/json":
+			return true
+		case "text/html":
+			return false
+		}
+	}
+	return false
+}
+
+// browsePage is data of the HTML listing.
+type browsePage struct {
+	browseResponse
+	Container string
+}
+
+var browseTemplate = template.Must(template.New("browse").Funcs(template.FuncMap{
+	"escapePath": escapePath,
+	"parent":     parentPath,
+	"size":       formatSize,
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Index of {{.Container}}/{{.Prefix}}</title>
+</head>
+<body>
+<h1>Index of {{.Container}}/{{.Prefix}}</h1>
+<p><a href="/zip/{{escapePath .Container}}/{{escapePath .Prefix}}">Download all in zip</a></p>
+<table>
+<tr><th>Name</th><th>Size</th><th>Modified</th><th></th></tr>
+{{- if .Prefix}}
+<tr><td><a href="/browse/{{escapePath .Container}}/{{escapePath (parent .Prefix)}}">../</a></td><td></td><td></td><td></td></tr>
+{{- end}}
+{{- range .Directories}}
+<tr><td><a href="/browse/{{escapePath $.Container}}/{{escapePath .Path}}">{{.Name}}</a></td><td>{{size .Size}}</td><td></td><td><a href="/zip/{{escapePath $.Container}}/{{escapePath .Path}}">zip</a></td></tr>
+{{- end}}
+{{- range .Files}}
+<tr><td><a href="/get/{{escapePath $.Container}}/{{.ObjectID}}">{{.Name}}</a></td><td>{{size .Size}}</td><td>{{with .Modified}}{{.Format "2006-01-02 15:04:05"}}{{end}}</td><td><a href="/get/{{escapePath $.Container}}/{{.ObjectID}}?download=true">download</a></td></tr>
+{{- end}}
+</table>
+{{- with .NextOffset}}
+<p>The listing is truncated, see the <a href="/browse/{{escapePath $.Container}}/{{escapePath $.Prefix}}?offset={{.}}&limit={{$.Limit}}">next page</a>.</p>
+{{- end}}
+</body>
+</html>
+`))
+
+// escapePath escapes every segment of the path to be used in URL.
+func escapePath(p string) string {
+	segments := strings.Split(p, "/")
+	for i := range segments {
+		segments[i] = url.PathEscape(segments[i])
+	}
+	return strings.Join(segments, "/")
+}
+
+// parentPath returns the parent directory of the directory path.
+func parentPath(dir string) string {
+	dir = strings.TrimSuffix(dir, "/")
+	if ind := strings.LastIndexByte(dir, '/'); ind >= 0 {
+		return dir[:ind+1]
+	}
+	return ""
+}
+
+// formatSize returns human-readable size.
+func formatSize(size uint64) string {
+	const unit = 1024
+	if size < unit {
+		return strconv.FormatUint(size, 10) + " B"
+	}
+
+	div, exp := uint64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+
+	return fmt.Sprintf("%.1f %ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,121 @@
+package downloader
+
+import (
+	"bytes"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	oidtest "github.com/nspcc-dev/neofs-sdk-go/object/id/test"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewBrowseResponse(t *testing.T) {
+	modified := time.Unix(1670000000, 0)
+
+	paths := []string{"dir/b.txt", "dir/sub/c.txt", "dir/a.txt", "dir/sub/d/e.txt", "dir/", "other/f.txt"}
+	ids := make([]oid.ID, len(paths)+1)
+	headers := make([]*object.Object, len(paths)+1)
+	for i, p := range paths {
+		ids[i] = oidtest.ID()
+		headers[i] = newArchiveTestObject(p, []byte(p), modified)
+	}
+	ids[len(paths)] = oidtest.ID() // failed to be received
+
+	resp := newBrowseResponse("dir/", ids, headers)
+	require.Equal(t, "dir/", resp.Prefix)
+
+	require.Equal(t, []browseDirectory{{
+		Name:    "sub/",
+		Path:    "dir/sub/",
+		Objects: 2,
+		Size:    uint64(len("dir/sub/c.txt") + len("dir/sub/d/e.txt")),
+	}}, resp.Directories)
+
+	require.Len(t, resp.Files, 2)
+	require.Equal(t, "a.txt", resp.Files[0].Name)
+	require.Equal(t, "dir/a.txt", resp.Files[0].Path)
+	require.Equal(t, ids[2].EncodeToString(), resp.Files[0].ObjectID)
+	require.True(t, modified.Equal(*resp.Files[0].Modified))
+	require.Equal(t, "b.txt", resp.Files[1].Name)
+
+	resp = newBrowseResponse("", ids, headers)
+	require.Len(t, resp.Directories, 2)
+	require.Empty(t, resp.Files)
+}
+
+func TestAcceptsJSON(t *testing.T) {
+	require.True(t, acceptsJSON("application/json"))
+	require.True(t, acceptsJSON("application/json;q=0.9, text/html"))
+	require.False(t, acceptsJSON("text/html,application/xhtml+xml,application/json"))
+	require.False(t, acceptsJSON("*/*"))
+	require.False(t, acceptsJSON(""))
+}
+
+func TestParentPath(t *testing.T) {
+	require.Equal(t, "a/", parentPath("a/b/"))
+	require.Equal(t, "", parentPath("a/"))
+}
+
+func TestBrowseTemplate(t *testing.T) {
+	next := 10
+	page := browsePage{
+		browseResponse: browseResponse{
+			Prefix:      "dir a/",
+			Directories: []browseDirectory{},
+			Files:       []browseFile{},
+			Limit:       10,
+			NextOffset:  &next,
+		},
+		Container: "cnr",
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, browseTemplate.Execute(&buf, page))
+	require.Contains(t, buf.String(), `href="/browse/cnr/dir%20a/?offset=10&amp;limit=10"`)
+
+	page.NextOffset = nil
+	buf.Reset()
+	require.NoError(t, browseTemplate.Execute(&buf, page))
+	require.NotContains(t, buf.String(), "offset=")
+}
+
+func TestBrowsePaginate(t *testing.T) {
+	newResponse := func() browseResponse {
+		return browseResponse{
+			Directories: []browseDirectory{{Name: "a/"}, {Name: "b/"}},
+			Files:       []browseFile{{Name: "c.txt"}, {Name: "d.txt"}, {Name: "e.txt"}},
+		}
+	}
+
+	names := func(resp browseResponse) []string {
+		res := []string{}
+		for _, dir := range resp.Directories {
+			res = append(res, dir.Name)
+		}
+		for _, file := range resp.Files {
+			res = append(res, file.Name)
+		}
+		return res
+	}
+
+	for _, tc := range []struct {
+		offset, limit int
+		expected      []string
+		hasMore       bool
+	}{
+		{offset: 0, limit: 1, expected: []string{"a/"}, hasMore: true},
+		{offset: 1, limit: 2, expected: []string{"b/", "c.txt"}, hasMore: true},
+		{offset: 3, limit: 2, expected: []string{"d.txt", "e.txt"}},
+		{offset: 0, limit: 10, expected: []string{"a/", "b/", "c.txt", "d.txt", "e.txt"}},
+		{offset: 10, limit: 10, expected: []string{}},
+		{offset: math.MaxInt, limit: 10, expected: []string{}},
+	} {
+		resp := newResponse()
+		hasMore := resp.paginate(tc.offset, tc.limit)
+		require.Equal(t, tc.expected, names(resp), "offset %d, limit %d", tc.offset, tc.limit)
+		require.Equal(t, tc.hasMore, hasMore, "offset %d, limit %d", tc.offset, tc.limit)
+	}
+}
@@ -0,0 +1,153 @@
+package downloader
+
+import (
+	"encoding/hex"
+	"net/http"
+	"net/textproto"
+	"strings"
+	"time"
+
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	"github.com/valyala/fasthttp"
+)
+
+// etag returns a strong entity tag of the object. It's based on the payload
+// checksum if it's present and on the object ID otherwise.
+func etag(obj *object.Object) string {
+	if cs, ok := obj.PayloadChecksum(); ok && len(cs.Value()) != 0 {
+		return `"` + hex.EncodeToString(cs.Value()) + `"`
+	}
+
+	objID, _ := obj.ID()
+	return `"` + objID.EncodeToString() + `"`
+}
+
+// scanETag determines if a syntactically valid ETag is present at s. If so,
+// the ETag and remaining text after consuming ETag is returned. Otherwise,
+// it returns "", "".
+func scanETag(s string) (etag string, remain string) {
+	s = strings.TrimLeft(s, " \t")
+	start := 0
+	if strings.HasPrefix(s, "W/") {
+		start = 2
+	}
+	if len(s[start:]) < 2 || s[start] != '"' {
+		return "", ""
+	}
+	// ETag is either W/"text" or "text".
+	// See RFC 7232 2.3.
+	for i := start + 1; i < len(s); i++ {
+		c := s[i]
+		switch {
+		// Character values allowed in ETags.
+		case c == 0x21 || c >= 0x23 && c <= 0x7E || c >= 0x80:
+		case c == '"':
+			return s[:i+1], s[i+1:]
+		default:
+			return "", ""
+		}
+	}
+	return "", ""
+}
+
+// etagStrongMatch reports whether a and b match using strong ETag comparison.
+func etagStrongMatch(a, b string) bool {
+	return a == b && a != "" && a[0] == '"'
+}
+
+// etagWeakMatch reports whether a and b match using weak ETag comparison.
+func etagWeakMatch(a, b string) bool {
+	return strings.TrimPrefix(a, "W/") == strings.TrimPrefix(b, "W/")
+}
+
+// matchETagList checks ETag against comma-separated list from If-Match or
+// If-None-Match headers using provided comparison function.
+func matchETagList(list, tag string, match func(a, b string) bool) bool {
+	for {
+		list = textproto.TrimString(list)
+		if len(list) == 0 {
+			return false
+		}
+		if list[0] == ',' {
+			list = list[1:]
+			continue
+		}
+		if list[0] == '*' {
+			return true
+		}
+		var candidate string
+		candidate, list = scanETag(list)
+		if candidate == "" {
+			return false
+		}
+		if match(candidate, tag) {
+			return true
+		}
+	}
+}
+
+// hasPreconditions checks if the request contains headers evaluated by
+// checkPreconditions.
+func hasPreconditions(header *fasthttp.RequestHeader) bool {
+	for _, key := range []string{
+		fasthttp.HeaderIfMatch,
+		fasthttp.HeaderIfNoneMatch,
+		fasthttp.HeaderIfModifiedSince,
+		fasthttp.HeaderIfUnmodifiedSince,
+	} {
+		if len(header.Peek(key)) != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// checkPreconditions evaluates conditional request headers against the
+// object as per RFC 7232 section 6. It sets ETag and Last-Modified headers
+// and returns false if the response has been already written, so the
+// object must not be sent.
+func (r request) checkPreconditions(obj *object.Object) bool {
+	tag := etag(obj)
+	r.Response.Header.Set(fasthttp.HeaderETag, tag)
+
+	modified, hasModified := lastModified(obj)
+	if hasModified {
+		r.Response.Header.Set(fasthttp.HeaderLastModified, modified.UTC().Format(http.TimeFormat))
+	}
+
+	header := &r.Request.Header
+
+	if im := string(header.Peek(fasthttp.HeaderIfMatch)); im != "" {
+		if !matchETagList(im, tag, etagStrongMatch) {
+			r.SetStatusCode(fasthttp.StatusPreconditionFailed)
+			return false
+		}
+	} else if ius := header.Peek(fasthttp.HeaderIfUnmodifiedSince); len(ius) != 0 && hasModified {
+		if t, err := time.Parse(http.TimeFormat, string(ius)); err == nil && modified.After(t) {
+			r.SetStatusCode(fasthttp.StatusPreconditionFailed)
+			return false
+		}
+	}
+
+	if inm := string(header.Peek(fasthttp.HeaderIfNoneMatch)); inm != "" {
+		if matchETagList(inm, tag, etagWeakMatch) {
+			r.notModified()
+			return false
+		}
+	} else if ims := header.Peek(fasthttp.HeaderIfModifiedSince); len(ims) != 0 && hasModified {
+		if t, err := time.Parse(http.TimeFormat, string(ims)); err == nil && !modified.After(t) {
+			r.notModified()
+			return false
+		}
+	}
+
+	return true
+}
+
+func (r request) notModified() {
+	if r.IsGet() || r.IsHead() {
+		r.SetStatusCode(fasthttp.StatusNotModified)
+		return
+	}
+	r.SetStatusCode(fasthttp.StatusPreconditionFailed)
+}
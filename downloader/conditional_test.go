@@ -0,0 +1,147 @@
+package downloader
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/nspcc-dev/neofs-sdk-go/checksum"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+)
+
+func TestMatchETagList(t *testing.T) {
+	const tag = `"abc"`
+
+	for _, tc := range []struct {
+		list     string
+		strong   bool
+		expected bool
+	}{
+		{list: `"abc"`, strong: true, expected: true},
+		{list: `"xyz", "abc"`, strong: true, expected: true},
+		{list: `W/"abc"`, strong: true, expected: false},
+		{list: `W/"abc"`, strong: false, expected: true},
+		{list: `*`, strong: true, expected: true},
+		{list: `"xyz"`, strong: false, expected: false},
+		{list: `abc`, strong: false, expected: false},
+	} {
+		match := etagWeakMatch
+		if tc.strong {
+			match = etagStrongMatch
+		}
+		require.Equal(t, tc.expected, matchETagList(tc.list, tag, match), tc.list)
+	}
+}
+
+func TestCheckPreconditions(t *testing.T) {
+	payloadHash := sha256.Sum256([]byte("payload"))
+	tag := `"` + hex.EncodeToString(payloadHash[:]) + `"`
+
+	var cs checksum.Checksum
+	cs.SetSHA256(payloadHash)
+
+	modified := time.Now().Add(-time.Hour).Truncate(time.Second)
+	timestamp := object.NewAttribute()
+	timestamp.SetKey(object.AttributeTimestamp)
+	timestamp.SetValue(strconv.FormatInt(modified.Unix(), 10))
+
+	obj := object.New()
+	obj.SetPayloadChecksum(cs)
+	obj.SetAttributes(*timestamp)
+
+	before := modified.Add(-time.Minute).UTC().Format(http.TimeFormat)
+	after := modified.Add(time.Minute).UTC().Format(http.TimeFormat)
+
+	for _, tc := range []struct {
+		name    string
+		headers map[string]string
+		passed  bool
+		status  int
+	}{
+		{
+			name:   "no conditions",
+			passed: true,
+		},
+		{
+			name:    "if-none-match matches",
+			headers: map[string]string{fasthttp.HeaderIfNoneMatch: tag},
+			status:  fasthttp.StatusNotModified,
+		},
+		{
+			name:    "if-none-match doesn't match",
+			headers: map[string]string{fasthttp.HeaderIfNoneMatch: `"other"`},
+			passed:  true,
+		},
+		{
+			name:    "if-modified-since not modified",
+			headers: map[string]string{fasthttp.HeaderIfModifiedSince: after},
+			status:  fasthttp.StatusNotModified,
+		},
+		{
+			name:    "if-modified-since modified",
+			headers: map[string]string{fasthttp.HeaderIfModifiedSince: before},
+			passed:  true,
+		},
+		{
+			name: "if-none-match has precedence",
+			headers: map[string]string{
+				fasthttp.HeaderIfNoneMatch:     `"other"`,
+				fasthttp.HeaderIfModifiedSince: after,
+			},
+			passed: true,
+		},
+		{
+			name:    "if-match matches",
+			headers: map[string]string{fasthttp.HeaderIfMatch: tag},
+			passed:  true,
+		},
+		{
+			name:    "if-match doesn't match",
+			headers: map[string]string{fasthttp.HeaderIfMatch: `"other"`},
+			status:  fasthttp.StatusPreconditionFailed,
+		},
+		{
+			name:    "if-unmodified-since modified",
+			headers: map[string]string{fasthttp.HeaderIfUnmodifiedSince: before},
+			status:  fasthttp.StatusPreconditionFailed,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := new(fasthttp.RequestCtx)
+			ctx.Request.Header.SetMethod(fasthttp.MethodGet)
+			for k, v := range tc.headers {
+				ctx.Request.Header.Set(k, v)
+			}
+
+			r := request{RequestCtx: ctx, log: zap.NewNop()}
+			require.Equal(t, tc.passed, r.checkPreconditions(obj))
+			require.Equal(t, tag, string(ctx.Response.Header.Peek(fasthttp.HeaderETag)))
+			if !tc.passed {
+				require.Equal(t, tc.status, ctx.Response.StatusCode())
+			}
+		})
+	}
+}
+
+func TestHasPreconditions(t *testing.T) {
+	var header fasthttp.RequestHeader
+	header.Set(fasthttp.HeaderRange, "bytes=0-10")
+	require.False(t, hasPreconditions(&header))
+
+	for _, key := range []string{
+		fasthttp.HeaderIfMatch,
+		fasthttp.HeaderIfNoneMatch,
+		fasthttp.HeaderIfModifiedSince,
+		fasthttp.HeaderIfUnmodifiedSince,
+	} {
+		var header fasthttp.RequestHeader
+		header.Set(key, "value")
+		require.True(t, hasPreconditions(&header), key)
+	}
+}
@@ -1,10 +1,9 @@
 package downloader
 
 import (
-	"archive/zip"
-	"bufio"
 	"bytes"
 	"context"
+	"encoding/base64"
 	"errors"
 	"fmt"
 	"io"
@@ -13,6 +12,7 @@ import (
 	"path"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 	"unicode"
 	"unicode/utf8"
@@ -92,10 +92,8 @@ func readContentType(maxSize uint64, rInit func(uint64) (io.Reader, error)) (str
 
 func (r request) receiveFile(clnt *pool.Pool, objectAddress oid.Address) {
 	var (
-		err      error
-		dis      = "inline"
-		start    = time.Now()
-		filename string
+		err   error
+		start = time.Now()
 	)
 	if err = tokens.StoreBearerToken(r.RequestCtx); err != nil {
 		r.log.Error("could not fetch and store bearer token", zap.Error(err))
@@ -103,9 +101,37 @@ func (r request) receiveFile(clnt *pool.Pool, objectAddress oid.Address) {
 		return
 	}
 
+	if rangeHeader := r.Request.Header.Peek(fasthttp.HeaderRange); len(rangeHeader) != 0 {
+		if r.receiveRange(clnt, objectAddress, string(rangeHeader)) {
+			return
+		}
+	}
+
+	btoken := bearerToken(r.RequestCtx)
+
+	if hasPreconditions(&r.Request.Header) {
+		// preconditions are evaluated by the header only, so the payload
+		// isn't requested for 304 and 412 responses
+		var prmHead pool.PrmObjectHead
+		prmHead.SetAddress(objectAddress)
+		if btoken != nil {
+			prmHead.UseBearer(*btoken)
+		}
+
+		obj, err := clnt.HeadObject(r.appCtx, prmHead)
+		if err != nil {
+			r.handleNeoFSErr(err, start)
+			return
+		}
+
+		if !r.checkPreconditions(&obj) {
+			return
+		}
+	}
+
 	var prm pool.PrmObjectGet
 	prm.SetAddress(objectAddress)
-	if btoken := bearerToken(r.RequestCtx); btoken != nil {
+	if btoken != nil {
 		prm.UseBearer(*btoken)
 	}
 
@@ -115,26 +141,67 @@ func (r request) receiveFile(clnt *pool.Pool, objectAddress oid.Address) {
 		return
 	}
 
-	// we can't close reader in this function, so how to do it?
-
-	if r.Request.URI().QueryArgs().GetBool("download") {
-		dis = "attachment"
+	if !r.checkPreconditions(&rObj.Header) {
+		if err = rObj.Payload.Close(); err != nil {
+			r.log.Debug("could not close object payload", zap.Error(err))
+		}
+		return
 	}
 
+	// we can't close reader in this function, so how to do it?
+
 	payloadSize := rObj.Header.PayloadSize()
 
 	r.Response.Header.Set(fasthttp.HeaderContentLength, strconv.FormatUint(payloadSize, 10))
-	var contentType string
-	for _, attr := range rObj.Header.Attributes() {
+	r.Response.Header.Set(fasthttp.HeaderAcceptRanges, bytesUnit)
+	filename, contentType := r.attributesToResponse(&rObj.Header)
+
+	idsToResponse(&r.Response, &rObj.Header)
+
+	if len(contentType) == 0 {
+		// determine the Content-Type from the payload head
+		var payloadHead []byte
+
+		contentType, payloadHead, err = readContentType(payloadSize, func(uint64) (io.Reader, error) {
+			return rObj.Payload, nil
+		})
+		if err != nil && err != io.EOF {
+			r.log.Error("could not detect Content-Type from payload", zap.Error(err))
+			response.Error(r.RequestCtx, "could not detect Content-Type from payload: "+err.Error(), fasthttp.StatusBadRequest)
+			return
+		}
+
+		// reset payload reader since a part of the data has been read
+		var headReader io.Reader = bytes.NewReader(payloadHead)
+
+		if err != io.EOF { // otherwise, we've already read full payload
+			headReader = io.MultiReader(headReader, rObj.Payload)
+		}
+
+		// note: we could do with io.Reader, but SetBodyStream below closes body stream
+		// if it implements io.Closer and that's useful for us.
+		rObj.Payload = readCloser{headReader, rObj.Payload}
+	}
+	r.SetContentType(contentType)
+
+	r.Response.Header.Set(fasthttp.HeaderContentDisposition, r.contentDisposition(filename))
+
+	r.Response.SetBodyStream(rObj.Payload, int(payloadSize))
+}
+
+// attributesToResponse sets object attributes as response headers.
+// Returns values of FileName and ContentType attributes.
+func (r request) attributesToResponse(obj *object.Object) (filename, contentType string) {
+	for _, attr := range obj.Attributes() {
 		key := attr.Key()
 		val := attr.Value()
-		if !isValidToken(key) || !isValidValue(val) {
+		if !isValidToken(key) {
 			continue
 		}
 		if strings.HasPrefix(key, utils.SystemAttributePrefix) {
 			key = systemBackwardTranslator(key)
 		}
-		r.Response.Header.Set(utils.UserAttributeHeaderPrefix+key, val)
+		r.Response.Header.Set(utils.UserAttributeHeaderPrefix+key, encodeHeaderValue(val))
 		switch key {
 		case object.AttributeFileName:
 			filename = val
@@ -150,41 +217,106 @@ func (r request) receiveFile(clnt *pool.Pool, objectAddress oid.Address) {
 			r.Response.Header.Set(fasthttp.HeaderLastModified,
 				time.Unix(value, 0).UTC().Format(http.TimeFormat))
 		case object.AttributeContentType:
-			contentType = val
+			if isValidValue(val) {
+				contentType = val
+			}
 		}
 	}
 
-	idsToResponse(&r.Response, &rObj.Header)
+	return filename, contentType
+}
 
-	if len(contentType) == 0 {
-		// determine the Content-Type from the payload head
-		var payloadHead []byte
+// contentDisposition returns Content-Disposition header value for the file.
+func (r request) contentDisposition(filename string) string {
+	dis := "inline"
+	if r.Request.URI().QueryArgs().GetBool("download") {
+		dis = "attachment"
+	}
 
-		contentType, payloadHead, err = readContentType(payloadSize, func(uint64) (io.Reader, error) {
-			return rObj.Payload, nil
-		})
-		if err != nil && err != io.EOF {
-			r.log.Error("could not detect Content-Type from payload", zap.Error(err))
-			response.Error(r.RequestCtx, "could not detect Content-Type from payload: "+err.Error(), fasthttp.StatusBadRequest)
-			return
-		}
+	return dis + "; " + dispositionFilename(path.Base(filename))
+}
 
-		// reset payload reader since a part of the data has been read
-		var headReader io.Reader = bytes.NewReader(payloadHead)
+// encodeHeaderValue returns the attribute value as it can be set in
+// the header. Values with characters we don't want to escape are encoded
+// according to RFC 2047 in UTF-8, the same encoding is decoded on upload.
+// Values which look like encoded-words are encoded too, so they are not
+// decoded by mistake. The value is always encoded as a single B-encoded word,
+// the limit of 75 characters per word is knowingly ignored to keep the value
+// in one piece.
+func encodeHeaderValue(val string) string {
+	if isValidValue(val) && !strings.Contains(val, "=?") {
+		return val
+	}
+	return "=?UTF-8?B?" + base64.StdEncoding.EncodeToString([]byte(val)) + "?="
+}
 
-		if err != io.EOF { // otherwise, we've already read full payload
-			headReader = io.MultiReader(headReader, rObj.Payload)
+// dispositionFilename returns filename parameter of Content-Disposition.
+// Non-ASCII names are encoded according to RFC 8187 with ASCII fallback
+// for clients which don't support it (see RFC 6266).
+func dispositionFilename(name string) string {
+	if isValidToken(name) {
+		return "filename=" + name
+	}
+
+	var (
+		fallback strings.Builder
+		ascii    = true
+	)
+	for _, c := range name {
+		switch {
+		case c < ' ' || c > '~':
+			ascii = false
+			fallback.WriteByte('_')
+		case c == '"' || c == '\\':
+			fallback.WriteByte('\\')
+			fallback.WriteRune(c)
+		default:
+			fallback.WriteRune(c)
 		}
+	}
 
-		// note: we could do with io.Reader, but SetBodyStream below closes body stream
-		// if it implements io.Closer and that's useful for us.
-		rObj.Payload = readCloser{headReader, rObj.Payload}
+	res := "filename=\"" + fallback.String() + "\""
+	if !ascii {
+		res += "; filename*=UTF-8''" + encodeExtValue(name)
 	}
-	r.SetContentType(contentType)
+	return res
+}
 
-	r.Response.Header.Set(fasthttp.HeaderContentDisposition, dis+"; filename="+path.Base(filename))
+// encodeExtValue percent-encodes all the bytes of the value except
+// attr-char defined in RFC 8187.
+func encodeExtValue(val string) string {
+	const hex = "0123456789ABCDEF"
+
+	var sb strings.Builder
+	for i := 0; i < len(val); i++ {
+		c := val[i]
+		if 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' ||
+			strings.IndexByte("!#$&+-.^_`|~", c) >= 0 {
+			sb.WriteByte(c)
+			continue
+		}
+		sb.WriteByte('%')
+		sb.WriteByte(hex[c>>4])
+		sb.WriteByte(hex[c&0xf])
+	}
+	return sb.String()
+}
 
-	r.Response.SetBodyStream(rObj.Payload, int(payloadSize))
+// lastModified returns the time from Timestamp attribute of the object.
+func lastModified(obj *object.Object) (time.Time, bool) {
+	for _, attr := range obj.Attributes() {
+		if attr.Key() != object.AttributeTimestamp {
+			continue
+		}
+
+		value, err := strconv.ParseInt(attr.Value(), 10, 64)
+		if err != nil {
+			return time.Time{}, false
+		}
+		return time.Unix(value, 0), true
+	}
+
+	return time.Time{}, false
 }
 
 // systemBackwardTranslator is used to convert headers looking like '__NEOFS__ATTR_NAME' to 'Neofs-Attr-Name'.
@@ -247,11 +379,39 @@ type Downloader struct {
 	pool              *pool.Pool
 	containerResolver *resolver.ContainerResolver
 	settings          *Settings
+	metrics           Metrics
 }
 
+// Metrics collects statistics of downloads.
+type Metrics interface {
+	AddArchiveObject(size uint64)
+	AddArchiveFailure()
+	ObserveArchiveDuration(time.Duration)
+}
+
+type nopMetrics struct{}
+
+func (nopMetrics) AddArchiveObject(uint64)              {}
+func (nopMetrics) AddArchiveFailure()                   {}
+func (nopMetrics) ObserveArchiveDuration(time.Duration) {}
+
 // Settings stores reloading parameters, so it has to provide atomic getters and setters.
 type Settings struct {
-	zipCompression atomic.Bool
+	zipCompression        atomic.Bool
+	multipleObjectsPolicy atomic.String
+	archiveWorkers        atomic.Int32
+	archiveMemoryBudget   atomic.Uint64
+	archiveErrorPolicy    atomic.String
+
+	websiteEnabled       atomic.Bool
+	websiteIndexDocument atomic.String
+	websiteErrorDocument atomic.String
+
+	mu                sync.RWMutex
+	websiteContainers map[string]struct{}
+	websiteCache      *websiteCache
+	hostContainers    map[string]string
+	hostDomains       []string
 }
 
 func (s *Settings) ZipCompression() bool {
@@ -262,14 +422,85 @@ func (s *Settings) SetZipCompression(val bool) {
 	s.zipCompression.Store(val)
 }
 
+// ArchiveWorkers returns the number of workers prefetching objects for archive.
+func (s *Settings) ArchiveWorkers() int {
+	return int(s.archiveWorkers.Load())
+}
+
+func (s *Settings) SetArchiveWorkers(val int) {
+	s.archiveWorkers.Store(int32(val))
+}
+
+// ArchiveMemoryBudget returns the maximum total size of object payloads
+// prefetched for archive.
+func (s *Settings) ArchiveMemoryBudget() uint64 {
+	return s.archiveMemoryBudget.Load()
+}
+
+func (s *Settings) SetArchiveMemoryBudget(val uint64) {
+	s.archiveMemoryBudget.Store(val)
+}
+
+// WebsiteEnabled returns true if website mode is enabled for all containers.
+func (s *Settings) WebsiteEnabled() bool {
+	return s.websiteEnabled.Load()
+}
+
+func (s *Settings) SetWebsiteEnabled(val bool) {
+	s.websiteEnabled.Store(val)
+}
+
+func (s *Settings) WebsiteIndexDocument() string {
+	return s.websiteIndexDocument.Load()
+}
+
+func (s *Settings) SetWebsiteIndexDocument(val string) {
+	s.websiteIndexDocument.Store(val)
+}
+
+func (s *Settings) WebsiteErrorDocument() string {
+	return s.websiteErrorDocument.Load()
+}
+
+func (s *Settings) SetWebsiteErrorDocument(val string) {
+	s.websiteErrorDocument.Store(val)
+}
+
+// WebsiteContainer returns true if website mode is enabled for the
+// container with the specified ID or name.
+func (s *Settings) WebsiteContainer(cnr string) bool {
+	s.mu.RLock()
+	_, ok := s.websiteContainers[cnr]
+	s.mu.RUnlock()
+	return ok
+}
+
+// SetWebsiteContainers sets IDs or names of containers website mode is enabled for.
+func (s *Settings) SetWebsiteContainers(containers []string) {
+	m := make(map[string]struct{}, len(containers))
+	for _, cnr := range containers {
+		m[cnr] = struct{}{}
+	}
+
+	s.mu.Lock()
+	s.websiteContainers = m
+	s.mu.Unlock()
+}
+
 // New creates an instance of Downloader using specified options.
-func New(ctx context.Context, params *utils.AppParams, settings *Settings) *Downloader {
+// Metrics can be nil.
+func New(ctx context.Context, params *utils.AppParams, settings *Settings, metrics Metrics) *Downloader {
+	if metrics == nil {
+		metrics = nopMetrics{}
+	}
+
 	return &Downloader{
 		appCtx:            ctx,
 		log:               params.Logger,
 		pool:              params.Pool,
 		settings:          settings,
 		containerResolver: params.Resolver,
+		metrics:           metrics,
 	}
 }
 
@@ -337,198 +568,62 @@ func (d *Downloader) byAttribute(c *fasthttp.RequestCtx, f func(request, *pool.P
 		return
 	}
 
-	res, err := d.search(c, containerID, key, val, object.MatchStringEqual)
+	objID, err := d.findObject(c, containerID, key, val)
 	if err != nil {
-		log.Error("could not search for objects", zap.Error(err))
-		response.Error(c, "could not search for objects: "+err.Error(), fasthttp.StatusBadRequest)
-		return
-	}
-
-	defer res.Close()
-
-	buf := make([]oid.ID, 1)
-
-	n, err := res.Read(buf)
-	if n == 0 {
-		if errors.Is(err, io.EOF) {
-			log.Error("object not found", zap.Error(err))
-			response.Error(c, "object not found", fasthttp.StatusNotFound)
-			return
-		}
-
-		log.Error("read object list failed", zap.Error(err))
-		response.Error(c, "read object list failed: "+err.Error(), fasthttp.StatusBadRequest)
+		d.handleFindErr(c, log, err)
 		return
 	}
 
 	var addrObj oid.Address
 	addrObj.SetContainer(*containerID)
-	addrObj.SetObject(buf[0])
+	addrObj.SetObject(objID)
 
 	f(*d.newRequest(c, log), d.pool, addrObj)
 }
 
-func (d *Downloader) search(c *fasthttp.RequestCtx, cid *cid.ID, key, val string, op object.SearchMatchType) (pool.ResObjectSearch, error) {
-	filters := object.NewSearchFilters()
-	filters.AddRootFilter()
-	filters.AddFilter(key, val, op)
-
-	var prm pool.PrmObjectSearch
-	prm.SetContainerID(*cid)
-	prm.SetFilters(filters)
-	if btoken := bearerToken(c); btoken != nil {
-		prm.UseBearer(*btoken)
-	}
+// errObjectNotFound is returned by findObject if there are no objects
+// matching the attribute.
+var errObjectNotFound = errors.New("object not found")
 
-	return d.pool.SearchObjects(d.appCtx, prm)
-}
-
-func (d *Downloader) getContainer(cnrID cid.ID) (container.Container, error) {
-	var prm pool.PrmContainerGet
-	prm.SetContainerID(cnrID)
-
-	return d.pool.GetContainer(d.appCtx, prm)
-}
-
-func (d *Downloader) addObjectToZip(zw *zip.Writer, obj *object.Object) (io.Writer, error) {
-	method := zip.Store
-	if d.settings.ZipCompression() {
-		method = zip.Deflate
-	}
-
-	filePath := getZipFilePath(obj)
-	if len(filePath) == 0 || filePath[len(filePath)-1] == '/' {
-		return nil, fmt.Errorf("invalid filepath '%s'", filePath)
-	}
-
-	return zw.CreateHeader(&zip.FileHeader{
-		Name:     filePath,
-		Method:   method,
-		Modified: time.Now(),
-	})
-}
-
-// DownloadZipped handles zip by prefix requests.
-func (d *Downloader) DownloadZipped(c *fasthttp.RequestCtx) {
-	scid, _ := c.UserValue("cid").(string)
-	prefix, _ := url.QueryUnescape(c.UserValue("prefix").(string))
-	log := d.log.With(zap.String("cid", scid), zap.String("prefix", prefix))
-
-	containerID, err := utils.GetContainerID(d.appCtx, scid, d.containerResolver)
-	if err != nil {
-		log.Error("wrong container id", zap.Error(err))
-		response.Error(c, "wrong container id", fasthttp.StatusBadRequest)
-		return
-	}
-
-	if err = tokens.StoreBearerToken(c); err != nil {
-		log.Error("could not fetch and store bearer token", zap.Error(err))
-		response.Error(c, "could not fetch and store bearer token: "+err.Error(), fasthttp.StatusBadRequest)
+func (d *Downloader) handleFindErr(c *fasthttp.RequestCtx, log *zap.Logger, err error) {
+	if errors.Is(err, errObjectNotFound) {
+		log.Error("object not found", zap.Error(err))
+		response.Error(c, "object not found", fasthttp.StatusNotFound)
 		return
 	}
 
-	// check if container exists here to be able to return 404 error,
-	// otherwise we get this error only in object iteration step
-	// and client get 200 OK.
-	if _, err = d.getContainer(*containerID); err != nil {
-		log.Error("could not check container existence", zap.Error(err))
-		if client.IsErrContainerNotFound(err) {
-			response.Error(c, "Not Found", fasthttp.StatusNotFound)
-			return
-		}
-		response.Error(c, "could not check container existence: "+err.Error(), fasthttp.StatusBadRequest)
+	var errMultiple *multipleObjectsError
+	if errors.As(err, &errMultiple) {
+		d.handleMultipleObjects(c, log, errMultiple)
 		return
 	}
 
-	resSearch, err := d.search(c, containerID, object.AttributeFilePath, prefix, object.MatchCommonPrefix)
-	if err != nil {
-		log.Error("could not search for objects", zap.Error(err))
-		response.Error(c, "could not search for objects: "+err.Error(), fasthttp.StatusBadRequest)
+	if errors.Is(err, errTooManyObjects) {
+		log.Error("could not select the newest object", zap.Error(err))
+		response.Error(c, err.Error(), fasthttp.StatusConflict)
 		return
 	}
 
-	c.Response.Header.Set(fasthttp.HeaderContentType, "application/zip")
-	c.Response.Header.Set(fasthttp.HeaderContentDisposition, "attachment; filename=\"archive.zip\"")
-	c.Response.SetStatusCode(http.StatusOK)
-
-	c.SetBodyStreamWriter(func(w *bufio.Writer) {
-		defer resSearch.Close()
-
-		zipWriter := zip.NewWriter(w)
-
-		var bufZip []byte
-		var addr oid.Address
-
-		empty := true
-		called := false
-		btoken := bearerToken(c)
-		addr.SetContainer(*containerID)
-
-		errIter := resSearch.Iterate(func(id oid.ID) bool {
-			called = true
-
-			if empty {
-				bufZip = make([]byte, 3<<20) // the same as for upload
-			}
-			empty = false
-
-			addr.SetObject(id)
-			if err = d.zipObject(zipWriter, addr, btoken, bufZip); err != nil {
-				log.Error("failed to add object to archive", zap.String("oid", id.EncodeToString()), zap.Error(err))
-			}
-
-			return false
-		})
-		if errIter != nil {
-			log.Error("iterating over selected objects failed", zap.Error(errIter))
-		} else if !called {
-			log.Error("objects not found")
-		}
-
-		if err = zipWriter.Close(); err != nil {
-			log.Error("close zip writer", zap.Error(err))
-		}
-	})
+	log.Error("could not find object", zap.Error(err))
+	response.Error(c, err.Error(), fasthttp.StatusBadRequest)
 }
 
-func (d *Downloader) zipObject(zipWriter *zip.Writer, addr oid.Address, btoken *bearer.Token, bufZip []byte) error {
-	var prm pool.PrmObjectGet
-	prm.SetAddress(addr)
-	if btoken != nil {
+// searchObjects searches for objects in the container using the bearer token
+// from the request context.
+func (d *Downloader) searchObjects(c *fasthttp.RequestCtx, cid *cid.ID, filters object.SearchFilters) (pool.ResObjectSearch, error) {
+	var prm pool.PrmObjectSearch
+	prm.SetContainerID(*cid)
+	prm.SetFilters(filters)
+	if btoken := bearerToken(c); btoken != nil {
 		prm.UseBearer(*btoken)
 	}
 
-	resGet, err := d.pool.GetObject(d.appCtx, prm)
-	if err != nil {
-		return fmt.Errorf("get NeoFS object: %v", err)
-	}
-
-	objWriter, err := d.addObjectToZip(zipWriter, &resGet.Header)
-	if err != nil {
-		return fmt.Errorf("zip create header: %v", err)
-	}
-
-	if _, err = io.CopyBuffer(objWriter, resGet.Payload, bufZip); err != nil {
-		return fmt.Errorf("copy object payload to zip file: %v", err)
-	}
-
-	if err = resGet.Payload.Close(); err != nil {
-		return fmt.Errorf("object body close error: %w", err)
-	}
-
-	if err = zipWriter.Flush(); err != nil {
-		return fmt.Errorf("flush zip writer: %v", err)
-	}
-
-	return nil
+	return d.pool.SearchObjects(d.appCtx, prm)
 }
 
-func getZipFilePath(obj *object.Object) string {
-	for _, attr := range obj.Attributes() {
-		if attr.Key() == object.AttributeFilePath {
-			return attr.Value()
-		}
-	}
+func (d *Downloader) getContainer(cnrID cid.ID) (container.Container, error) {
+	var prm pool.PrmContainerGet
+	prm.SetContainerID(cnrID)
 
-	return ""
+	return d.pool.GetContainer(d.appCtx, prm)
 }
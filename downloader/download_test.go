@@ -1,6 +1,7 @@
 package downloader
 
 import (
+	"mime"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -21,3 +22,37 @@ func TestSystemBackwardTranslator(t *testing.T) {
 		require.Equal(t, expected[i], res)
 	}
 }
+
+func TestEncodeHeaderValue(t *testing.T) {
+	for _, tc := range []struct {
+		val      string
+		expected string
+	}{
+		{val: "value", expected: "value"},
+		{val: "файл.txt", expected: "=?UTF-8?B?0YTQsNC50LsudHh0?="},
+		{val: "a \"quoted\" value", expected: "=?UTF-8?B?YSAicXVvdGVkIiB2YWx1ZQ==?="},
+		{val: "=?UTF-8?B?dGVzdA==?=", expected: "=?UTF-8?B?PT9VVEYtOD9CP2RHVnpkQT09Pz0=?="},
+	} {
+		enc := encodeHeaderValue(tc.val)
+		require.Equal(t, tc.expected, enc)
+
+		dec, err := new(mime.WordDecoder).DecodeHeader(enc)
+		require.NoError(t, err)
+		require.Equal(t, tc.val, dec)
+	}
+}
+
+func TestDispositionFilename(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		expected string
+	}{
+		{name: "file.txt", expected: "filename=file.txt"},
+		{name: "my file.txt", expected: `filename="my file.txt"`},
+		{name: `say "hi".txt`, expected: `filename="say \"hi\".txt"`},
+		{name: "файл.txt", expected: `filename="____.txt"; filename*=UTF-8''%D1%84%D0%B0%D0%B9%D0%BB.txt`},
+		{name: "文件 1.txt", expected: `filename="__ 1.txt"; filename*=UTF-8''%E6%96%87%E4%BB%B6%201.txt`},
+	} {
+		require.Equal(t, tc.expected, dispositionFilename(tc.name))
+	}
+}
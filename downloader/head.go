@@ -1,15 +1,14 @@
 package downloader
 
 import (
+	"context"
 	"io"
-	"net/http"
 	"strconv"
-	"strings"
 	"time"
 
 	"github.com/nspcc-dev/neofs-http-gw/response"
 	"github.com/nspcc-dev/neofs-http-gw/tokens"
-	"github.com/nspcc-dev/neofs-http-gw/utils"
+	"github.com/nspcc-dev/neofs-sdk-go/bearer"
 	"github.com/nspcc-dev/neofs-sdk-go/object"
 	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
 	"github.com/nspcc-dev/neofs-sdk-go/pool"
@@ -48,51 +47,18 @@ func (r request) headObject(clnt *pool.Pool, objectAddress oid.Address) {
 		return
 	}
 
-	r.Response.Header.Set(fasthttp.HeaderContentLength, strconv.FormatUint(obj.PayloadSize(), 10))
-	var contentType string
-	for _, attr := range obj.Attributes() {
-		key := attr.Key()
-		val := attr.Value()
-		if !isValidToken(key) || !isValidValue(val) {
-			continue
-		}
-		if strings.HasPrefix(key, utils.SystemAttributePrefix) {
-			key = systemBackwardTranslator(key)
-		}
-		r.Response.Header.Set(utils.UserAttributeHeaderPrefix+key, val)
-		switch key {
-		case object.AttributeTimestamp:
-			value, err := strconv.ParseInt(val, 10, 64)
-			if err != nil {
-				r.log.Info("couldn't parse creation date",
-					zap.String("key", key),
-					zap.String("val", val),
-					zap.Error(err))
-				continue
-			}
-			r.Response.Header.Set(fasthttp.HeaderLastModified, time.Unix(value, 0).UTC().Format(http.TimeFormat))
-		case object.AttributeContentType:
-			contentType = val
-		}
+	if !r.checkPreconditions(&obj) {
+		return
 	}
 
+	r.Response.Header.Set(fasthttp.HeaderContentLength, strconv.FormatUint(obj.PayloadSize(), 10))
+	r.Response.Header.Set(fasthttp.HeaderAcceptRanges, bytesUnit)
+	_, contentType := r.attributesToResponse(&obj)
+
 	idsToResponse(&r.Response, &obj)
 
 	if len(contentType) == 0 {
-		contentType, _, err = readContentType(obj.PayloadSize(), func(sz uint64) (io.Reader, error) {
-			var prmRange pool.PrmObjectRange
-			prmRange.SetAddress(objectAddress)
-			prmRange.SetLength(sz)
-			if btoken != nil {
-				prmRange.UseBearer(*btoken)
-			}
-
-			resObj, err := clnt.ObjectRange(r.appCtx, prmRange)
-			if err != nil {
-				return nil, err
-			}
-			return &resObj, nil
-		})
+		contentType, err = detectContentType(r.appCtx, clnt, objectAddress, btoken, obj.PayloadSize())
 		if err != nil && err != io.EOF {
 			r.handleNeoFSErr(err, start)
 			return
@@ -101,6 +67,16 @@ func (r request) headObject(clnt *pool.Pool, objectAddress oid.Address) {
 	r.SetContentType(contentType)
 }
 
+// detectContentType determines the Content-Type of the object using the
+// payload head received via range request.
+func detectContentType(ctx context.Context, clnt *pool.Pool, addr oid.Address, btoken *bearer.Token, payloadSize uint64) (string, error) {
+	contentType, _, err := readContentType(payloadSize, func(sz uint64) (io.Reader, error) {
+		return getRange(ctx, clnt, addr, btoken, httpRange{length: sz})
+	})
+
+	return contentType, err
+}
+
 func idsToResponse(resp *fasthttp.Response, obj *object.Object) {
 	objID, _ := obj.ID()
 	cnrID, _ := obj.ContainerID()
@@ -0,0 +1,152 @@
+package downloader
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	"github.com/nspcc-dev/neofs-http-gw/response"
+	"github.com/nspcc-dev/neofs-http-gw/tokens"
+	"github.com/nspcc-dev/neofs-sdk-go/checksum"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	"github.com/nspcc-dev/neofs-sdk-go/pool"
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+)
+
+type (
+	// objectMeta is a complete object header. Unlike X-Attribute-* headers,
+	// it contains all the attributes as they are.
+	objectMeta struct {
+		ObjectID        string                `json:"object_id,omitempty"`
+		ContainerID     string                `json:"container_id,omitempty"`
+		OwnerID         string                `json:"owner_id,omitempty"`
+		Version         string                `json:"version,omitempty"`
+		Type            string                `json:"type"`
+		CreationEpoch   uint64                `json:"creation_epoch"`
+		PayloadSize     uint64                `json:"payload_size"`
+		PayloadChecksum *objectMetaChecksum   `json:"payload_checksum,omitempty"`
+		HomomorphicHash *objectMetaChecksum   `json:"homomorphic_hash,omitempty"`
+		Attributes      []objectMetaAttribute `json:"attributes"`
+		Split           *objectMetaSplit      `json:"split,omitempty"`
+	}
+
+	objectMetaChecksum struct {
+		Type  string `json:"type"`
+		Value string `json:"value"`
+	}
+
+	objectMetaAttribute struct {
+		Key   string `json:"key"`
+		Value string `json:"value"`
+	}
+
+	objectMetaSplit struct {
+		SplitID    string      `json:"split_id,omitempty"`
+		ParentID   string      `json:"parent_id,omitempty"`
+		PreviousID string      `json:"previous_id,omitempty"`
+		Children   []string    `json:"children,omitempty"`
+		Parent     *objectMeta `json:"parent,omitempty"`
+	}
+)
+
+// DownloadMeta handles requests to get the object header in JSON.
+func (d *Downloader) DownloadMeta(c *fasthttp.RequestCtx) {
+	d.byAddress(c, request.objectMeta)
+}
+
+func (r request) objectMeta(clnt *pool.Pool, objectAddress oid.Address) {
+	var start = time.Now()
+	if err := tokens.StoreBearerToken(r.RequestCtx); err != nil {
+		r.log.Error("could not fetch and store bearer token", zap.Error(err))
+		response.Error(r.RequestCtx, "could not fetch and store bearer token", fasthttp.StatusBadRequest)
+		return
+	}
+
+	var prm pool.PrmObjectHead
+	prm.SetAddress(objectAddress)
+	if btoken := bearerToken(r.RequestCtx); btoken != nil {
+		prm.UseBearer(*btoken)
+	}
+
+	obj, err := clnt.HeadObject(r.appCtx, prm)
+	if err != nil {
+		r.handleNeoFSErr(err, start)
+		return
+	}
+
+	meta := newObjectMeta(&obj)
+	if meta.ObjectID == "" {
+		meta.ObjectID = objectAddress.Object().EncodeToString()
+	}
+
+	r.SetContentType("application/json")
+	enc := json.NewEncoder(r)
+	enc.SetIndent("", "\t")
+	if err = enc.Encode(meta); err != nil {
+		r.log.Error("could not encode response", zap.Error(err))
+		response.Error(r.RequestCtx, "could not encode response", fasthttp.StatusInternalServerError)
+	}
+}
+
+func newObjectMeta(obj *object.Object) *objectMeta {
+	meta := &objectMeta{
+		Type:          obj.Type().String(),
+		CreationEpoch: obj.CreationEpoch(),
+		PayloadSize:   obj.PayloadSize(),
+		Attributes:    []objectMetaAttribute{},
+	}
+
+	if id, ok := obj.ID(); ok {
+		meta.ObjectID = id.EncodeToString()
+	}
+	if cnrID, ok := obj.ContainerID(); ok {
+		meta.ContainerID = cnrID.EncodeToString()
+	}
+	if owner := obj.OwnerID(); owner != nil {
+		meta.OwnerID = owner.EncodeToString()
+	}
+	if ver := obj.Version(); ver != nil {
+		meta.Version = ver.String()
+	}
+	if cs, ok := obj.PayloadChecksum(); ok {
+		meta.PayloadChecksum = newObjectMetaChecksum(cs)
+	}
+	if cs, ok := obj.PayloadHomomorphicHash(); ok {
+		meta.HomomorphicHash = newObjectMetaChecksum(cs)
+	}
+
+	for _, attr := range obj.Attributes() {
+		meta.Attributes = append(meta.Attributes, objectMetaAttribute{Key: attr.Key(), Value: attr.Value()})
+	}
+
+	var split objectMetaSplit
+	if splitID := obj.SplitID(); splitID != nil {
+		split.SplitID = splitID.String()
+	}
+	if id, ok := obj.ParentID(); ok {
+		split.ParentID = id.EncodeToString()
+	}
+	if id, ok := obj.PreviousID(); ok {
+		split.PreviousID = id.EncodeToString()
+	}
+	for _, id := range obj.Children() {
+		split.Children = append(split.Children, id.EncodeToString())
+	}
+	if parent := obj.Parent(); parent != nil {
+		split.Parent = newObjectMeta(parent)
+	}
+	if split.SplitID != "" || split.ParentID != "" || split.PreviousID != "" || len(split.Children) != 0 || split.Parent != nil {
+		meta.Split = &split
+	}
+
+	return meta
+}
+
+func newObjectMetaChecksum(cs checksum.Checksum) *objectMetaChecksum {
+	return &objectMetaChecksum{
+		Type:  cs.Type().String(),
+		Value: hex.EncodeToString(cs.Value()),
+	}
+}
@@ -0,0 +1,70 @@
+package downloader
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+
+	"github.com/nspcc-dev/neofs-sdk-go/checksum"
+	cidtest "github.com/nspcc-dev/neofs-sdk-go/container/id/test"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	oidtest "github.com/nspcc-dev/neofs-sdk-go/object/id/test"
+	usertest "github.com/nspcc-dev/neofs-sdk-go/user/test"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewObjectMeta(t *testing.T) {
+	cnrID, owner := cidtest.ID(), usertest.ID()
+
+	attrName := object.NewAttribute()
+	attrName.SetKey(object.AttributeFileName)
+	attrName.SetValue("файл.txt")
+
+	attrInvalid := object.NewAttribute()
+	attrInvalid.SetKey("Key With Spaces")
+	attrInvalid.SetValue("line\nbreak")
+
+	parent := object.New()
+	parent.SetContainerID(cnrID)
+	parent.SetAttributes(*attrName)
+
+	var cs checksum.Checksum
+	cs.SetSHA256(sha256.Sum256([]byte("content")))
+
+	obj := object.New()
+	obj.SetID(oidtest.ID())
+	obj.SetContainerID(cnrID)
+	obj.SetOwnerID(owner)
+	obj.SetCreationEpoch(10)
+	obj.SetPayloadSize(7)
+	obj.SetPayloadChecksum(cs)
+	obj.SetAttributes(*attrName, *attrInvalid)
+	obj.SetSplitID(object.NewSplitID())
+	obj.SetParentID(oidtest.ID())
+	obj.SetParent(parent)
+
+	meta := newObjectMeta(obj)
+
+	id, _ := obj.ID()
+	require.Equal(t, id.EncodeToString(), meta.ObjectID)
+	require.Equal(t, cnrID.EncodeToString(), meta.ContainerID)
+	require.Equal(t, owner.EncodeToString(), meta.OwnerID)
+	require.Equal(t, "REGULAR", meta.Type)
+	require.EqualValues(t, 10, meta.CreationEpoch)
+	require.EqualValues(t, 7, meta.PayloadSize)
+	require.Equal(t, &objectMetaChecksum{Type: "SHA256", Value: hex.EncodeToString(cs.Value())}, meta.PayloadChecksum)
+	require.Nil(t, meta.HomomorphicHash)
+	require.Equal(t, []objectMetaAttribute{
+		{Key: object.AttributeFileName, Value: "файл.txt"},
+		{Key: "Key With Spaces", Value: "line\nbreak"},
+	}, meta.Attributes)
+
+	require.NotNil(t, meta.Split)
+	require.Equal(t, obj.SplitID().String(), meta.Split.SplitID)
+	require.NotEmpty(t, meta.Split.ParentID)
+	require.NotNil(t, meta.Split.Parent)
+	require.Nil(t, meta.Split.Parent.Split)
+	require.Equal(t, []objectMetaAttribute{{Key: object.AttributeFileName, Value: "файл.txt"}}, meta.Split.Parent.Attributes)
+
+	require.Nil(t, newObjectMeta(object.New()).Split)
+}
@@ -0,0 +1,256 @@
+package downloader
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/nspcc-dev/neofs-sdk-go/bearer"
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	"github.com/nspcc-dev/neofs-sdk-go/pool"
+	"go.uber.org/atomic"
+)
+
+type (
+	// prefetcher fetches objects for the archive by several workers ahead of
+	// the archive writer. Entries are returned in the order they were added.
+	prefetcher struct {
+		d      *Downloader
+		btoken *bearer.Token
+		budget *memoryBudget
+
+		queue    chan *prefetchEntry
+		work     chan *prefetchEntry
+		wg       sync.WaitGroup
+		canceled atomic.Bool
+	}
+
+	// prefetchEntry is an object prefetched for the archive. Payload is nil
+	// if the object doesn't fit the memory budget, so it must be fetched
+	// by the archive writer.
+	prefetchEntry struct {
+		item     *archiveItem
+		payload  []byte
+		reserved uint64
+		err      error
+		done     chan struct{}
+	}
+
+	// memoryBudget limits the total size of prefetched payloads.
+	memoryBudget struct {
+		free atomic.Int64
+	}
+)
+
+func newMemoryBudget(size uint64) *memoryBudget {
+	b := new(memoryBudget)
+	b.free.Store(int64(size))
+	return b
+}
+
+// tryAcquire reserves n bytes of the budget if it's possible.
+func (b *memoryBudget) tryAcquire(n uint64) bool {
+	for {
+		free := b.free.Load()
+		if free < 0 || n > uint64(free) {
+			return false
+		}
+		if b.free.CompareAndSwap(free, free-int64(n)) {
+			return true
+		}
+	}
+}
+
+func (b *memoryBudget) release(n uint64) {
+	b.free.Add(int64(n))
+}
+
+// newPrefetcher creates prefetcher and starts its workers according to the settings.
+func (d *Downloader) newPrefetcher(btoken *bearer.Token) *prefetcher {
+	workers := d.settings.ArchiveWorkers()
+	if workers <= 0 {
+		workers = 1
+	}
+
+	p := &prefetcher{
+		d:      d,
+		btoken: btoken,
+		budget: newMemoryBudget(d.settings.ArchiveMemoryBudget()),
+		queue:  make(chan *prefetchEntry, workers),
+		work:   make(chan *prefetchEntry),
+	}
+
+	p.wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer p.wg.Done()
+			for entry := range p.work {
+				p.fetch(entry)
+				close(entry.done)
+			}
+		}()
+	}
+
+	return p
+}
+
+// add schedules the object to be prefetched. It blocks if there are too
+// many entries not consumed by the archive writer.
+func (p *prefetcher) add(item *archiveItem) {
+	entry := &prefetchEntry{
+		item: item,
+		done: make(chan struct{}),
+	}
+
+	p.queue <- entry
+	p.work <- entry
+}
+
+// close must be called after all objects are added.
+func (p *prefetcher) close() {
+	close(p.work)
+	close(p.queue)
+	p.wg.Wait()
+}
+
+// entries returns prefetched objects in the order they were added.
+// Every entry must be released after it's written to the archive.
+func (p *prefetcher) entries() <-chan *prefetchEntry {
+	return p.queue
+}
+
+// cancel stops fetching of objects. Entries must still be consumed and
+// released, but they have neither payload nor error.
+func (p *prefetcher) cancel() {
+	p.canceled.Store(true)
+}
+
+// wait blocks until the entry is prefetched.
+func (p *prefetcher) wait(entry *prefetchEntry) {
+	<-entry.done
+}
+
+// release frees memory budget reserved by the entry.
+func (p *prefetcher) release(entry *prefetchEntry) {
+	p.budget.release(entry.reserved)
+	entry.payload = nil
+}
+
+func (p *prefetcher) fetch(entry *prefetchEntry) {
+	if p.canceled.Load() {
+		return
+	}
+
+	size := entry.item.header.PayloadSize()
+	if !p.budget.tryAcquire(size) {
+		// the object is fetched when it's written to the archive
+		return
+	}
+
+	entry.reserved = size
+
+	resGet, err := p.d.getObject(entry.item.addr, p.btoken)
+	if err != nil {
+		entry.err = err
+		return
+	}
+
+	defer resGet.Payload.Close()
+
+	payload := make([]byte, size)
+	if _, err = io.ReadFull(resGet.Payload, payload); err != nil {
+		entry.err = fmt.Errorf("read object payload: %w", err)
+		return
+	}
+	entry.payload = payload
+}
+
+// payloadReader returns reader of the entry payload. It fetches the object
+// if its payload isn't prefetched.
+func (p *prefetcher) payloadReader(entry *prefetchEntry) (io.ReadCloser, error) {
+	if entry.err != nil {
+		return nil, entry.err
+	}
+
+	if entry.payload != nil {
+		return io.NopCloser(bytes.NewReader(entry.payload)), nil
+	}
+
+	resGet, err := p.d.getObject(entry.item.addr, p.btoken)
+	if err != nil {
+		return nil, err
+	}
+
+	return resGet.Payload, nil
+}
+
+func (d *Downloader) getObject(addr oid.Address, btoken *bearer.Token) (*pool.ResGetObject, error) {
+	var prm pool.PrmObjectGet
+	prm.SetAddress(addr)
+	if btoken != nil {
+		prm.UseBearer(*btoken)
+	}
+
+	resGet, err := d.pool.GetObject(d.appCtx, prm)
+	if err != nil {
+		return nil, fmt.Errorf("get NeoFS object: %v", err)
+	}
+
+	return &resGet, nil
+}
+
+// headObjects receives headers of the objects by several workers according to
+// the settings.
+func (d *Downloader) headObjects(cnrID cid.ID, ids []oid.ID, btoken *bearer.Token) ([]*object.Object, []error) {
+	headers := make([]*object.Object, len(ids))
+	errs := make([]error, len(ids))
+
+	workers := d.settings.ArchiveWorkers()
+	if workers <= 0 {
+		workers = 1
+	}
+
+	var (
+		wg      sync.WaitGroup
+		indexes = make(chan int)
+	)
+
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				var addr oid.Address
+				addr.SetContainer(cnrID)
+				addr.SetObject(ids[i])
+				headers[i], errs[i] = d.getHeader(addr, btoken)
+			}
+		}()
+	}
+
+	for i := range ids {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	return headers, errs
+}
+
+func (d *Downloader) getHeader(addr oid.Address, btoken *bearer.Token) (*object.Object, error) {
+	var prm pool.PrmObjectHead
+	prm.SetAddress(addr)
+	if btoken != nil {
+		prm.UseBearer(*btoken)
+	}
+
+	obj, err := d.pool.HeadObject(d.appCtx, prm)
+	if err != nil {
+		return nil, fmt.Errorf("head NeoFS object: %v", err)
+	}
+
+	return &obj, nil
+}
@@ -0,0 +1,313 @@
+package downloader
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nspcc-dev/neofs-http-gw/response"
+	"github.com/nspcc-dev/neofs-sdk-go/bearer"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	"github.com/nspcc-dev/neofs-sdk-go/pool"
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+)
+
+const (
+	bytesUnit          = "bytes"
+	multipartByteRange = "multipart/byteranges; boundary="
+)
+
+var (
+	errInvalidRange = errors.New("invalid range")
+	errNoOverlap    = errors.New("invalid range: failed to overlap")
+)
+
+// httpRange specifies the byte range to be sent to the client.
+type httpRange struct {
+	start, length uint64
+}
+
+func (r httpRange) contentRange(size uint64) string {
+	return fmt.Sprintf("%s %d-%d/%d", bytesUnit, r.start, r.start+r.length-1, size)
+}
+
+func (r httpRange) mimeHeader(contentType string, size uint64) textproto.MIMEHeader {
+	return textproto.MIMEHeader{
+		fasthttp.HeaderContentRange: {r.contentRange(size)},
+		fasthttp.HeaderContentType:  {contentType},
+	}
+}
+
+// parseRange parses a Range header string as per RFC 7233.
+// errNoOverlap is returned if none of the ranges overlap the payload.
+func parseRange(s string, size uint64) ([]httpRange, error) {
+	if s == "" {
+		return nil, nil // header not present
+	}
+	const b = bytesUnit + "="
+	if !strings.HasPrefix(s, b) {
+		return nil, errInvalidRange
+	}
+
+	var ranges []httpRange
+	noOverlap := false
+	for _, ra := range strings.Split(s[len(b):], ",") {
+		ra = textproto.TrimString(ra)
+		if ra == "" {
+			continue
+		}
+
+		i := strings.Index(ra, "-")
+		if i < 0 {
+			return nil, errInvalidRange
+		}
+
+		start, end := textproto.TrimString(ra[:i]), textproto.TrimString(ra[i+1:])
+		var r httpRange
+		if start == "" {
+			// If no start is specified, end specifies the
+			// range start relative to the end of the payload,
+			// and we are dealing with <suffix-length>
+			// which has to be a non-negative integer as per
+			// RFC 7233 Section 2.1 "Byte-Ranges".
+			if end == "" || end[0] == '-' {
+				return nil, errInvalidRange
+			}
+			i, err := strconv.ParseUint(end, 10, 64)
+			if err != nil {
+				return nil, errInvalidRange
+			}
+			if i == 0 {
+				noOverlap = true
+				continue
+			}
+			if i > size {
+				i = size
+			}
+			r.start = size - i
+			r.length = size - r.start
+		} else {
+			i, err := strconv.ParseUint(start, 10, 64)
+			if err != nil {
+				return nil, errInvalidRange
+			}
+			if i >= size {
+				// If the range begins after the size of the payload,
+				// there is no overlap.
+				noOverlap = true
+				continue
+			}
+			r.start = i
+			if end == "" {
+				// If no end is specified, range extends to end of the payload.
+				r.length = size - r.start
+			} else {
+				i, err := strconv.ParseUint(end, 10, 64)
+				if err != nil || r.start > i {
+					return nil, errInvalidRange
+				}
+				if i >= size {
+					i = size - 1
+				}
+				r.length = i - r.start + 1
+			}
+		}
+		ranges = append(ranges, r)
+	}
+
+	if noOverlap && len(ranges) == 0 {
+		// The specified ranges did not overlap with the payload.
+		return nil, errNoOverlap
+	}
+
+	return ranges, nil
+}
+
+func sumRangesSize(ranges []httpRange) (size uint64) {
+	for _, ra := range ranges {
+		size += ra.length
+	}
+	return
+}
+
+// countingWriter counts how many bytes have been written to it.
+type countingWriter uint64
+
+func (w *countingWriter) Write(p []byte) (n int, err error) {
+	*w += countingWriter(len(p))
+	return len(p), nil
+}
+
+// rangesMIMESize returns the number of bytes it takes to encode the
+// provided ranges as a multipart response.
+func rangesMIMESize(ranges []httpRange, contentType string, size uint64) uint64 {
+	var w countingWriter
+	mw := multipart.NewWriter(&w)
+	for _, ra := range ranges {
+		_, _ = mw.CreatePart(ra.mimeHeader(contentType, size))
+		w += countingWriter(ra.length)
+	}
+	_ = mw.Close()
+	return uint64(w)
+}
+
+// checkIfRange reports whether the Range header must be taken into account
+// according to If-Range precondition.
+func (r request) checkIfRange(obj *object.Object) bool {
+	ir := string(r.Request.Header.Peek(fasthttp.HeaderIfRange))
+	if ir == "" {
+		return true
+	}
+
+	if strings.HasPrefix(ir, `"`) || strings.HasPrefix(ir, "W/") {
+		tag, _ := scanETag(ir)
+		return etagStrongMatch(tag, etag(obj))
+	}
+
+	modified, ok := lastModified(obj)
+	if !ok {
+		return false
+	}
+
+	t, err := time.Parse(http.TimeFormat, ir)
+	if err != nil {
+		return false
+	}
+
+	return t.Unix() == modified.Unix()
+}
+
+// receiveRange sends requested payload ranges of the object to the client.
+// Returns false if the ranges should be ignored and the whole payload
+// should be sent instead.
+func (r request) receiveRange(clnt *pool.Pool, objectAddress oid.Address, rangeHeader string) bool {
+	var start = time.Now()
+
+	btoken := bearerToken(r.RequestCtx)
+
+	var prm pool.PrmObjectHead
+	prm.SetAddress(objectAddress)
+	if btoken != nil {
+		prm.UseBearer(*btoken)
+	}
+
+	obj, err := clnt.HeadObject(r.appCtx, prm)
+	if err != nil {
+		r.handleNeoFSErr(err, start)
+		return true
+	}
+
+	if !r.checkPreconditions(&obj) {
+		return true
+	}
+
+	if !r.checkIfRange(&obj) {
+		return false
+	}
+
+	payloadSize := obj.PayloadSize()
+
+	ranges, err := parseRange(rangeHeader, payloadSize)
+	if err != nil {
+		r.log.Debug("could not parse range", zap.String("range", rangeHeader), zap.Error(err))
+		r.Response.Header.Set(fasthttp.HeaderContentRange, fmt.Sprintf("%s */%d", bytesUnit, payloadSize))
+		response.Error(r.RequestCtx, err.Error(), fasthttp.StatusRequestedRangeNotSatisfiable)
+		return true
+	}
+
+	if len(ranges) == 0 || sumRangesSize(ranges) > payloadSize {
+		// The total number of bytes in all the ranges is larger than
+		// the size of the payload, so send the whole payload instead.
+		return false
+	}
+
+	filename, contentType := r.attributesToResponse(&obj)
+	idsToResponse(&r.Response, &obj)
+
+	if len(contentType) == 0 {
+		contentType, err = detectContentType(r.appCtx, clnt, objectAddress, btoken, payloadSize)
+		if err != nil && err != io.EOF {
+			r.handleNeoFSErr(err, start)
+			return true
+		}
+	}
+
+	r.Response.Header.Set(fasthttp.HeaderAcceptRanges, bytesUnit)
+	r.Response.Header.Set(fasthttp.HeaderContentDisposition, r.contentDisposition(filename))
+	r.SetStatusCode(fasthttp.StatusPartialContent)
+
+	if len(ranges) == 1 {
+		ra := ranges[0]
+
+		resRange, err := getRange(r.appCtx, clnt, objectAddress, btoken, ra)
+		if err != nil {
+			r.handleNeoFSErr(err, start)
+			return true
+		}
+
+		r.SetContentType(contentType)
+		r.Response.Header.Set(fasthttp.HeaderContentRange, ra.contentRange(payloadSize))
+		r.Response.SetBodyStream(resRange, int(ra.length))
+		return true
+	}
+
+	sendSize := rangesMIMESize(ranges, contentType, payloadSize)
+	pr, pw := io.Pipe()
+	mw := multipart.NewWriter(pw)
+	r.SetContentType(multipartByteRange + mw.Boundary())
+
+	go func() {
+		for _, ra := range ranges {
+			part, err := mw.CreatePart(ra.mimeHeader(contentType, payloadSize))
+			if err != nil {
+				_ = pw.CloseWithError(err)
+				return
+			}
+
+			resRange, err := getRange(r.appCtx, clnt, objectAddress, btoken, ra)
+			if err != nil {
+				r.log.Error("could not receive object range", zap.Error(err))
+				_ = pw.CloseWithError(err)
+				return
+			}
+
+			_, err = io.CopyN(part, resRange, int64(ra.length))
+			_ = resRange.Close()
+			if err != nil {
+				_ = pw.CloseWithError(err)
+				return
+			}
+		}
+		_ = mw.Close()
+		_ = pw.Close()
+	}()
+
+	r.Response.SetBodyStream(pr, int(sendSize))
+	return true
+}
+
+func getRange(ctx context.Context, clnt *pool.Pool, addr oid.Address, btoken *bearer.Token, ra httpRange) (*pool.ResObjectRange, error) {
+	var prm pool.PrmObjectRange
+	prm.SetAddress(addr)
+	prm.SetOffset(ra.start)
+	prm.SetLength(ra.length)
+	if btoken != nil {
+		prm.UseBearer(*btoken)
+	}
+
+	res, err := clnt.ObjectRange(ctx, prm)
+	if err != nil {
+		return nil, err
+	}
+
+	return &res, nil
+}
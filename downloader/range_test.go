@@ -0,0 +1,102 @@
+package downloader
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRange(t *testing.T) {
+	const size = 100
+
+	for _, tc := range []struct {
+		name     string
+		header   string
+		expected []httpRange
+		err      error
+	}{
+		{
+			name: "empty header",
+		},
+		{
+			name:     "single range",
+			header:   "bytes=0-9",
+			expected: []httpRange{{start: 0, length: 10}},
+		},
+		{
+			name:     "open range",
+			header:   "bytes=90-",
+			expected: []httpRange{{start: 90, length: 10}},
+		},
+		{
+			name:     "suffix range",
+			header:   "bytes=-5",
+			expected: []httpRange{{start: 95, length: 5}},
+		},
+		{
+			name:     "suffix range larger than payload",
+			header:   "bytes=-500",
+			expected: []httpRange{{start: 0, length: size}},
+		},
+		{
+			name:     "end beyond payload",
+			header:   "bytes=50-1000",
+			expected: []httpRange{{start: 50, length: 50}},
+		},
+		{
+			name:     "multiple ranges",
+			header:   "bytes=0-0, 10-19,-1",
+			expected: []httpRange{{start: 0, length: 1}, {start: 10, length: 10}, {start: 99, length: 1}},
+		},
+		{
+			name:     "partially overlapped ranges",
+			header:   "bytes=0-1,200-300",
+			expected: []httpRange{{start: 0, length: 2}},
+		},
+		{
+			name:   "no overlap",
+			header: "bytes=100-200",
+			err:    errNoOverlap,
+		},
+		{
+			name:   "zero suffix",
+			header: "bytes=-0",
+			err:    errNoOverlap,
+		},
+		{
+			name:   "wrong unit",
+			header: "items=0-1",
+			err:    errInvalidRange,
+		},
+		{
+			name:   "reversed range",
+			header: "bytes=10-5",
+			err:    errInvalidRange,
+		},
+		{
+			name:   "no dash",
+			header: "bytes=10",
+			err:    errInvalidRange,
+		},
+		{
+			name:   "negative start",
+			header: "bytes=--5",
+			err:    errInvalidRange,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ranges, err := parseRange(tc.header, size)
+			if tc.err != nil {
+				require.ErrorIs(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, ranges)
+		})
+	}
+}
+
+func TestContentRange(t *testing.T) {
+	ra := httpRange{start: 10, length: 5}
+	require.Equal(t, "bytes 10-14/100", ra.contentRange(100))
+}
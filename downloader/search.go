@@ -0,0 +1,319 @@
+package downloader
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/nspcc-dev/neofs-http-gw/response"
+	"github.com/nspcc-dev/neofs-http-gw/tokens"
+	"github.com/nspcc-dev/neofs-http-gw/utils"
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+)
+
+const (
+	searchFilterParam     = "filter"
+	searchAttributesParam = "attrs"
+	searchOffsetParam     = "offset"
+	searchLimitParam      = "limit"
+
+	defaultSearchLimit = 100
+	maxSearchLimit     = 1000
+)
+
+// searchMatchTypes maps match types of the search filter to NeoFS ones.
+var searchMatchTypes = map[string]object.SearchMatchType{
+	"eq":         object.MatchStringEqual,
+	"ne":         object.MatchStringNotEqual,
+	"prefix":     object.MatchCommonPrefix,
+	"notpresent": object.MatchNotPresent,
+}
+
+type (
+	searchResponse struct {
+		ContainerID string         `json:"container_id"`
+		Objects     []searchObject `json:"objects"`
+		Offset      int            `json:"offset"`
+		Limit       int            `json:"limit"`
+		NextOffset  *int           `json:"next_offset,omitempty"`
+	}
+
+	searchObject struct {
+		ObjectID   string            `json:"object_id"`
+		Attributes map[string]string `json:"attributes,omitempty"`
+		Error      string            `json:"error,omitempty"`
+	}
+)
+
+// Search handles search requests returning list of objects matching the
+// filters in JSON.
+func (d *Downloader) Search(c *fasthttp.RequestCtx) {
+	var (
+		scid, _ = c.UserValue("cid").(string)
+		log     = d.log.With(zap.String("cid", scid))
+	)
+
+	containerID, err := utils.GetContainerID(d.appCtx, scid, d.containerResolver)
+	if err != nil {
+		log.Error("wrong container id", zap.Error(err))
+		response.Error(c, "wrong container id", fasthttp.StatusBadRequest)
+		return
+	}
+
+	args := c.QueryArgs()
+
+	filters, err := parseSearchFilters(args)
+	if err != nil {
+		log.Error("invalid search filters", zap.Error(err))
+		response.Error(c, "invalid search filters: "+err.Error(), fasthttp.StatusBadRequest)
+		return
+	}
+
+	offset, limit, err := parsePagination(args, defaultSearchLimit)
+	if err != nil {
+		log.Error("invalid pagination parameters", zap.Error(err))
+		response.Error(c, err.Error(), fasthttp.StatusBadRequest)
+		return
+	}
+
+	if err = tokens.StoreBearerToken(c); err != nil {
+		log.Error("could not fetch and store bearer token", zap.Error(err))
+		response.Error(c, "could not fetch and store bearer token: "+err.Error(), fasthttp.StatusBadRequest)
+		return
+	}
+
+	// check if container exists here to be able to return 404 error
+	if _, err = d.getContainer(*containerID); err != nil {
+		log.Error("could not check container existence", zap.Error(err))
+		d.handleContainerErr(c, err)
+		return
+	}
+
+	ids, hasMore, err := d.searchPage(c, containerID, filters, offset, limit)
+	if err != nil {
+		log.Error("could not search for objects", zap.Error(err))
+		response.Error(c, "could not search for objects: "+err.Error(), fasthttp.StatusBadRequest)
+		return
+	}
+
+	resp := searchResponse{
+		ContainerID: containerID.EncodeToString(),
+		Objects:     make([]searchObject, len(ids)),
+		Offset:      offset,
+		Limit:       limit,
+	}
+	if hasMore {
+		next := offset + limit
+		resp.NextOffset = &next
+	}
+
+	for i, id := range ids {
+		resp.Objects[i].ObjectID = id.EncodeToString()
+	}
+
+	if keys := parseAttributeKeys(args); len(keys) > 0 {
+		headers, errs := d.headObjects(*containerID, ids, bearerToken(c))
+		for i := range ids {
+			if errs[i] != nil {
+				log.Warn("could not head object", zap.String("oid", resp.Objects[i].ObjectID), zap.Error(errs[i]))
+				resp.Objects[i].Error = errs[i].Error()
+				continue
+			}
+			resp.Objects[i].Attributes = selectAttributes(headers[i], keys)
+		}
+	}
+
+	c.SetContentType("application/json")
+	enc := json.NewEncoder(c)
+	enc.SetIndent("", "\t")
+	if err = enc.Encode(resp); err != nil {
+		log.Error("could not encode response", zap.Error(err))
+		response.Error(c, "could not encode response", fasthttp.StatusInternalServerError)
+	}
+}
+
+// searchPage searches for objects and returns IDs of the objects from the
+// requested page. Search results are unordered, so all found IDs are sorted
+// to make pages stable. The second return value is true if there are more
+// objects after the page.
+func (d *Downloader) searchPage(c *fasthttp.RequestCtx, cnrID *cid.ID, filters object.SearchFilters, offset, limit int) ([]oid.ID, bool, error) {
+	ids, err := d.searchAll(c, cnrID, filters)
+	if err != nil {
+		return nil, false, err
+	}
+
+	sortObjectIDs(ids)
+	ids, hasMore := pageObjectIDs(ids, offset, limit)
+
+	return ids, hasMore, nil
+}
+
+// sortObjectIDs sorts object IDs by their string representation.
+func sortObjectIDs(ids []oid.ID) {
+	keys := make(map[oid.ID]string, len(ids))
+	for _, id := range ids {
+		keys[id] = id.EncodeToString()
+	}
+
+	sort.Slice(ids, func(i, j int) bool {
+		return keys[ids[i]] < keys[ids[j]]
+	})
+}
+
+// pageObjectIDs returns the requested page of object IDs. The second return
+// value is true if there are more objects after the page.
+func pageObjectIDs(ids []oid.ID, offset, limit int) ([]oid.ID, bool) {
+	if offset >= len(ids) {
+		return nil, false
+	}
+
+	ids = ids[offset:]
+	if len(ids) > limit {
+		return ids[:limit], true
+	}
+
+	return ids, false
+}
+
+// searchAll searches for objects and returns IDs of all found objects.
+func (d *Downloader) searchAll(c *fasthttp.RequestCtx, cnrID *cid.ID, filters object.SearchFilters) ([]oid.ID, error) {
+	res, err := d.searchObjects(c, cnrID, filters)
+	if err != nil {
+		return nil, err
+	}
+
+	defer res.Close()
+
+	var ids []oid.ID
+	err = res.Iterate(func(id oid.ID) bool {
+		ids = append(ids, id)
+		return false
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+// searchFirst searches for objects and returns ID of the first found one,
+// the search is stopped after it. The second return value is false if
+// nothing is found.
+func (d *Downloader) searchFirst(c *fasthttp.RequestCtx, cnrID *cid.ID, filters object.SearchFilters) (oid.ID, bool, error) {
+	res, err := d.searchObjects(c, cnrID, filters)
+	if err != nil {
+		return oid.ID{}, false, err
+	}
+
+	defer res.Close()
+
+	var (
+		id    oid.ID
+		found bool
+	)
+	err = res.Iterate(func(objID oid.ID) bool {
+		id, found = objID, true
+		return true
+	})
+	if err != nil {
+		return oid.ID{}, false, err
+	}
+
+	return id, found, nil
+}
+
+// selectAttributes returns values of the specified attributes of the object.
+func selectAttributes(obj *object.Object, keys []string) map[string]string {
+	res := make(map[string]string, len(keys))
+	for _, attr := range obj.Attributes() {
+		for _, key := range keys {
+			if attr.Key() == key {
+				res[key] = attr.Value()
+			}
+		}
+	}
+
+	return res
+}
+
+// parseSearchFilters parses search filters from query arguments. Every filter
+// has `key:match:value` format, the value is omitted for `notpresent` match.
+func parseSearchFilters(args *fasthttp.Args) (object.SearchFilters, error) {
+	filters := object.NewSearchFilters()
+	filters.AddRootFilter()
+
+	for _, raw := range args.PeekMulti(searchFilterParam) {
+		parts := strings.SplitN(string(raw), ":", 3)
+		if len(parts) < 2 || parts[0] == "" {
+			return nil, fmt.Errorf("invalid filter '%s'", raw)
+		}
+
+		match, err := searchMatchType(parts[1])
+		if err != nil {
+			return nil, err
+		}
+
+		var value string
+		if len(parts) == 3 {
+			value = parts[2]
+		}
+		if match != object.MatchNotPresent && len(parts) != 3 {
+			return nil, fmt.Errorf("missing value in filter '%s'", raw)
+		}
+
+		filters.AddFilter(parts[0], value, match)
+	}
+
+	return filters, nil
+}
+
+func searchMatchType(match string) (object.SearchMatchType, error) {
+	res, ok := searchMatchTypes[match]
+	if !ok {
+		return 0, fmt.Errorf("unknown match type '%s'", match)
+	}
+	return res, nil
+}
+
+// parsePagination parses offset and limit query arguments. The limit is
+// defaultLimit if it isn't specified.
+func parsePagination(args *fasthttp.Args, defaultLimit int) (offset, limit int, err error) {
+	limit = defaultLimit
+
+	if args.Has(searchOffsetParam) {
+		if offset, err = args.GetUint(searchOffsetParam); err != nil {
+			return 0, 0, errors.New("invalid offset: " + string(args.Peek(searchOffsetParam)))
+		}
+	}
+
+	if args.Has(searchLimitParam) {
+		if limit, err = args.GetUint(searchLimitParam); err != nil || limit == 0 {
+			return 0, 0, errors.New("invalid limit: " + string(args.Peek(searchLimitParam)))
+		}
+		if limit > maxSearchLimit {
+			limit = maxSearchLimit
+		}
+	}
+
+	return offset, limit, nil
+}
+
+// parseAttributeKeys returns attribute names from the comma-separated lists
+// of the attrs query arguments.
+func parseAttributeKeys(args *fasthttp.Args) []string {
+	var keys []string
+	for _, raw := range args.PeekMulti(searchAttributesParam) {
+		for _, key := range strings.Split(string(raw), ",") {
+			if key = strings.TrimSpace(key); key != "" {
+				keys = append(keys, key)
+			}
+		}
+	}
+	return keys
+}
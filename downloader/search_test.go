@@ -0,0 +1,119 @@
+package downloader
+
+import (
+	"testing"
+
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	oidtest "github.com/nspcc-dev/neofs-sdk-go/object/id/test"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+)
+
+func TestParseSearchFilters(t *testing.T) {
+	args := new(fasthttp.Args)
+	args.Add(searchFilterParam, "FileName:eq:cat.jpg")
+	args.Add(searchFilterParam, "FilePath:prefix:dir/a:b")
+	args.Add(searchFilterParam, "Type:notpresent")
+
+	filters, err := parseSearchFilters(args)
+	require.NoError(t, err)
+	require.Len(t, filters, 4) // including root filter
+
+	for i, expected := range []struct {
+		key, value string
+		match      object.SearchMatchType
+	}{
+		{key: "FileName", value: "cat.jpg", match: object.MatchStringEqual},
+		{key: "FilePath", value: "dir/a:b", match: object.MatchCommonPrefix},
+		{key: "Type", match: object.MatchNotPresent},
+	} {
+		require.Equal(t, expected.key, filters[i+1].Header())
+		require.Equal(t, expected.value, filters[i+1].Value())
+		require.Equal(t, expected.match, filters[i+1].Operation())
+	}
+
+	for _, filter := range []string{"FileName", ":eq:cat.jpg", "FileName:like:cat", "FileName:eq"} {
+		args := new(fasthttp.Args)
+		args.Add(searchFilterParam, filter)
+
+		_, err = parseSearchFilters(args)
+		require.Error(t, err, filter)
+	}
+}
+
+func TestParsePagination(t *testing.T) {
+	for _, tc := range []struct {
+		query  string
+		offset int
+		limit  int
+		err    bool
+	}{
+		{query: "", offset: 0, limit: defaultSearchLimit},
+		{query: "offset=10&limit=5", offset: 10, limit: 5},
+		{query: "limit=100000", limit: maxSearchLimit},
+		{query: "limit=0", err: true},
+		{query: "offset=-1", err: true},
+	} {
+		args := new(fasthttp.Args)
+		args.Parse(tc.query)
+
+		offset, limit, err := parsePagination(args, defaultSearchLimit)
+		if tc.err {
+			require.Error(t, err, tc.query)
+			continue
+		}
+		require.NoError(t, err, tc.query)
+		require.Equal(t, tc.offset, offset, tc.query)
+		require.Equal(t, tc.limit, limit, tc.query)
+	}
+}
+
+func TestSortObjectIDs(t *testing.T) {
+	ids := make([]oid.ID, 10)
+	for i := range ids {
+		ids[i] = oidtest.ID()
+	}
+
+	sortObjectIDs(ids)
+	for i := 1; i < len(ids); i++ {
+		require.Less(t, ids[i-1].EncodeToString(), ids[i].EncodeToString())
+	}
+}
+
+func TestPageObjectIDs(t *testing.T) {
+	ids := []oid.ID{oidtest.ID(), oidtest.ID(), oidtest.ID()}
+
+	for _, tc := range []struct {
+		offset, limit int
+		expected      []oid.ID
+		hasMore       bool
+	}{
+		{offset: 0, limit: 2, expected: ids[:2], hasMore: true},
+		{offset: 1, limit: 2, expected: ids[1:]},
+		{offset: 0, limit: 3, expected: ids},
+		{offset: 2, limit: 5, expected: ids[2:]},
+		{offset: 3, limit: 1},
+		{offset: 10, limit: 1},
+	} {
+		page, hasMore := pageObjectIDs(ids, tc.offset, tc.limit)
+		require.Equal(t, tc.expected, page, "offset %d, limit %d", tc.offset, tc.limit)
+		require.Equal(t, tc.hasMore, hasMore, "offset %d, limit %d", tc.offset, tc.limit)
+	}
+}
+
+func TestSelectAttributes(t *testing.T) {
+	fileName := object.NewAttribute()
+	fileName.SetKey(object.AttributeFileName)
+	fileName.SetValue("cat.jpg")
+
+	custom := object.NewAttribute()
+	custom.SetKey("MyAttribute")
+	custom.SetValue("value")
+
+	obj := object.New()
+	obj.SetAttributes(*fileName, *custom)
+
+	require.Equal(t, map[string]string{object.AttributeFileName: "cat.jpg"},
+		selectAttributes(obj, []string{object.AttributeFileName, "Missing"}))
+}
@@ -0,0 +1,187 @@
+package downloader
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/nspcc-dev/neofs-http-gw/response"
+	"github.com/nspcc-dev/neofs-sdk-go/bearer"
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+)
+
+// Policies of object selection when several objects match the attribute.
+const (
+	// PolicyFirst selects the first found object.
+	PolicyFirst = "first"
+	// PolicyNewest selects the object with the greatest Timestamp attribute.
+	PolicyNewest = "newest"
+	// PolicyMultipleChoices responds with 300 status and the list of objects.
+	PolicyMultipleChoices = "choices"
+	// PolicyConflict responds with 409 status.
+	PolicyConflict = "conflict"
+)
+
+// maxSelectionCandidates limits the number of objects to choose from.
+const maxSelectionCandidates = 1000
+
+// errTooManyObjects is returned by findObject if there are more objects
+// matching the attribute than can be compared to select the newest one.
+var errTooManyObjects = fmt.Errorf("more than %d objects match the attribute", maxSelectionCandidates)
+
+// multipleObjectsError is returned by findObject if several objects match
+// the attribute and the policy doesn't allow to choose one of them.
+type multipleObjectsError struct {
+	policy string
+	cnrID  cid.ID
+	ids    []oid.ID
+	// truncated is set if there are more matching objects than listed.
+	truncated bool
+}
+
+func (e *multipleObjectsError) Error() string {
+	if e.truncated {
+		return fmt.Sprintf("more than %d objects match the attribute", len(e.ids))
+	}
+	return fmt.Sprintf("%d objects match the attribute", len(e.ids))
+}
+
+// MultipleObjectsPolicy returns the policy of object selection when several
+// objects match the attribute.
+func (s *Settings) MultipleObjectsPolicy() string {
+	if policy := s.multipleObjectsPolicy.Load(); policy != "" {
+		return policy
+	}
+	return PolicyFirst
+}
+
+// SetMultipleObjectsPolicy sets the policy of object selection when several
+// objects match the attribute.
+func (s *Settings) SetMultipleObjectsPolicy(val string) error {
+	switch val {
+	case PolicyFirst, PolicyNewest, PolicyMultipleChoices, PolicyConflict:
+		s.multipleObjectsPolicy.Store(val)
+		return nil
+	default:
+		return fmt.Errorf("unknown multiple objects policy: %s", val)
+	}
+}
+
+// findObject searches for the object with the attribute of the specified
+// value. If there are several such objects, one of them is selected according
+// to the settings.
+func (d *Downloader) findObject(c *fasthttp.RequestCtx, cnrID *cid.ID, key, val string) (oid.ID, error) {
+	policy := d.settings.MultipleObjectsPolicy()
+
+	filters := object.NewSearchFilters()
+	filters.AddRootFilter()
+	filters.AddFilter(key, val, object.MatchStringEqual)
+
+	if policy == PolicyFirst {
+		// other objects don't matter, so they aren't even received
+		id, found, err := d.searchFirst(c, cnrID, filters)
+		if err != nil {
+			return oid.ID{}, fmt.Errorf("could not search for objects: %w", err)
+		} else if !found {
+			return oid.ID{}, errObjectNotFound
+		}
+		return id, nil
+	}
+
+	ids, hasMore, err := d.searchPage(c, cnrID, filters, 0, maxSelectionCandidates)
+	if err != nil {
+		return oid.ID{}, fmt.Errorf("could not search for objects: %w", err)
+	}
+
+	switch {
+	case len(ids) == 0:
+		return oid.ID{}, errObjectNotFound
+	case len(ids) == 1:
+		return ids[0], nil
+	case policy == PolicyNewest:
+		if hasMore {
+			// the newest object can be among the ones not received
+			return oid.ID{}, errTooManyObjects
+		}
+		return d.newestObject(*cnrID, ids, bearerToken(c))
+	default:
+		return oid.ID{}, &multipleObjectsError{policy: policy, cnrID: *cnrID, ids: ids, truncated: hasMore}
+	}
+}
+
+// newestObject receives headers of the objects and returns ID of the newest
+// one, see selectNewest.
+func (d *Downloader) newestObject(cnrID cid.ID, ids []oid.ID, btoken *bearer.Token) (oid.ID, error) {
+	headers, errs := d.headObjects(cnrID, ids, btoken)
+	for i, err := range errs {
+		if err != nil {
+			return oid.ID{}, fmt.Errorf("could not head object %s: %w", ids[i].EncodeToString(), err)
+		}
+	}
+
+	return selectNewest(ids, headers), nil
+}
+
+// selectNewest returns ID of the object with the greatest Timestamp
+// attribute. Objects without timestamp are considered the oldest ones,
+// objects with the same timestamp are ordered by ID.
+func selectNewest(ids []oid.ID, headers []*object.Object) oid.ID {
+	var (
+		newest    oid.ID
+		newestTS  int64
+		newestStr string
+	)
+
+	for i, id := range ids {
+		// invalid timestamp is treated as missing one
+		var ts int64
+		if t, ok := lastModified(headers[i]); ok {
+			ts = t.Unix()
+		}
+		idStr := id.EncodeToString()
+
+		if i == 0 || ts > newestTS || ts == newestTS && idStr > newestStr {
+			newest, newestTS, newestStr = id, ts, idStr
+		}
+	}
+
+	return newest
+}
+
+type multipleChoicesResponse struct {
+	ContainerID string         `json:"container_id"`
+	Objects     []searchObject `json:"objects"`
+	Truncated   bool           `json:"truncated,omitempty"`
+}
+
+// handleMultipleObjects responds with the list of objects and 300 status or
+// with 409 status depending on the policy.
+func (d *Downloader) handleMultipleObjects(c *fasthttp.RequestCtx, log *zap.Logger, err *multipleObjectsError) {
+	log.Error("several objects found", zap.Int("count", len(err.ids)))
+
+	if err.policy != PolicyMultipleChoices {
+		response.Error(c, err.Error(), fasthttp.StatusConflict)
+		return
+	}
+
+	resp := multipleChoicesResponse{
+		ContainerID: err.cnrID.EncodeToString(),
+		Objects:     make([]searchObject, len(err.ids)),
+		Truncated:   err.truncated,
+	}
+	for i, id := range err.ids {
+		resp.Objects[i].ObjectID = id.EncodeToString()
+	}
+
+	c.SetContentType("application/json")
+	c.SetStatusCode(fasthttp.StatusMultipleChoices)
+	enc := json.NewEncoder(c)
+	enc.SetIndent("", "\t")
+	if encErr := enc.Encode(resp); encErr != nil {
+		log.Error("could not encode response", zap.Error(encErr))
+		response.Error(c, "could not encode response", fasthttp.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,115 @@
+package downloader
+
+import (
+	"encoding/json"
+	"testing"
+
+	cidtest "github.com/nspcc-dev/neofs-sdk-go/container/id/test"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	oidtest "github.com/nspcc-dev/neofs-sdk-go/object/id/test"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+)
+
+func TestMultipleObjectsPolicy(t *testing.T) {
+	var s Settings
+	require.Equal(t, PolicyFirst, s.MultipleObjectsPolicy())
+
+	require.NoError(t, s.SetMultipleObjectsPolicy(PolicyNewest))
+	require.Equal(t, PolicyNewest, s.MultipleObjectsPolicy())
+
+	require.Error(t, s.SetMultipleObjectsPolicy("random"))
+	require.Equal(t, PolicyNewest, s.MultipleObjectsPolicy())
+}
+
+func TestHandleMultipleObjects(t *testing.T) {
+	d := &Downloader{}
+	errMultiple := &multipleObjectsError{
+		cnrID: cidtest.ID(),
+		ids:   []oid.ID{oidtest.ID(), oidtest.ID()},
+	}
+
+	t.Run("conflict", func(t *testing.T) {
+		errMultiple.policy = PolicyConflict
+
+		ctx := new(fasthttp.RequestCtx)
+		d.handleMultipleObjects(ctx, zap.NewNop(), errMultiple)
+		require.Equal(t, fasthttp.StatusConflict, ctx.Response.StatusCode())
+	})
+
+	t.Run("choices", func(t *testing.T) {
+		errMultiple.policy = PolicyMultipleChoices
+
+		ctx := new(fasthttp.RequestCtx)
+		d.handleMultipleObjects(ctx, zap.NewNop(), errMultiple)
+		require.Equal(t, fasthttp.StatusMultipleChoices, ctx.Response.StatusCode())
+
+		var resp multipleChoicesResponse
+		require.NoError(t, json.Unmarshal(ctx.Response.Body(), &resp))
+		require.Equal(t, errMultiple.cnrID.EncodeToString(), resp.ContainerID)
+		require.Len(t, resp.Objects, 2)
+		for i, id := range errMultiple.ids {
+			require.Equal(t, id.EncodeToString(), resp.Objects[i].ObjectID)
+		}
+		require.False(t, resp.Truncated)
+	})
+
+	t.Run("choices truncated", func(t *testing.T) {
+		errMultiple.policy = PolicyMultipleChoices
+		errMultiple.truncated = true
+		defer func() { errMultiple.truncated = false }()
+
+		ctx := new(fasthttp.RequestCtx)
+		d.handleMultipleObjects(ctx, zap.NewNop(), errMultiple)
+		require.Equal(t, fasthttp.StatusMultipleChoices, ctx.Response.StatusCode())
+
+		var resp multipleChoicesResponse
+		require.NoError(t, json.Unmarshal(ctx.Response.Body(), &resp))
+		require.Len(t, resp.Objects, 2)
+		require.True(t, resp.Truncated)
+	})
+}
+
+func TestSelectNewest(t *testing.T) {
+	newObject := func(timestamp string) *object.Object {
+		obj := object.New()
+		if timestamp != "" {
+			attr := object.NewAttribute()
+			attr.SetKey(object.AttributeTimestamp)
+			attr.SetValue(timestamp)
+			obj.SetAttributes(*attr)
+		}
+		return obj
+	}
+
+	ids := []oid.ID{oidtest.ID(), oidtest.ID(), oidtest.ID()}
+
+	newest := selectNewest(ids, []*object.Object{newObject("10"), newObject("30"), newObject("20")})
+	require.Equal(t, ids[1], newest)
+
+	newest = selectNewest(ids, []*object.Object{newObject(""), newObject("invalid"), newObject("1")})
+	require.Equal(t, ids[2], newest)
+
+	sameTime := []*object.Object{newObject("10"), newObject("10"), newObject("10")}
+	expected := ids[0]
+	for _, id := range ids[1:] {
+		if id.EncodeToString() > expected.EncodeToString() {
+			expected = id
+		}
+	}
+	require.Equal(t, expected, selectNewest(ids, sameTime))
+}
+
+func TestHandleFindErr(t *testing.T) {
+	d := &Downloader{}
+
+	ctx := new(fasthttp.RequestCtx)
+	d.handleFindErr(ctx, zap.NewNop(), errTooManyObjects)
+	require.Equal(t, fasthttp.StatusConflict, ctx.Response.StatusCode())
+
+	ctx = new(fasthttp.RequestCtx)
+	d.handleFindErr(ctx, zap.NewNop(), errObjectNotFound)
+	require.Equal(t, fasthttp.StatusNotFound, ctx.Response.StatusCode())
+}
@@ -0,0 +1,116 @@
+package downloader
+
+import (
+	"net"
+	"strings"
+
+	"github.com/nspcc-dev/neofs-http-gw/response"
+	"github.com/nspcc-dev/neofs-http-gw/utils"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	"github.com/nspcc-dev/neofs-sdk-go/pool"
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+)
+
+// SetVirtualHosts sets host to container ID or name mapping and base domains
+// whose subdomains are treated as container names.
+func (s *Settings) SetVirtualHosts(hosts map[string]string, domains []string) {
+	m := make(map[string]string, len(hosts))
+	for host, cnr := range hosts {
+		m[strings.ToLower(host)] = cnr
+	}
+
+	d := make([]string, 0, len(domains))
+	for _, domain := range domains {
+		d = append(d, "."+strings.Trim(strings.ToLower(domain), "."))
+	}
+
+	s.mu.Lock()
+	s.hostContainers = m
+	s.hostDomains = d
+	s.mu.Unlock()
+}
+
+// VirtualHostContainer returns container ID or name the host is bound to.
+// The host is checked against explicit mapping first and then it's
+// checked to be a subdomain of one of the base domains.
+func (s *Settings) VirtualHostContainer(host string) (string, bool) {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if cnr, ok := s.hostContainers[host]; ok {
+		return cnr, true
+	}
+
+	for _, domain := range s.hostDomains {
+		if name := strings.TrimSuffix(host, domain); name != host && name != "" && !strings.Contains(name, ".") {
+			return name, true
+		}
+	}
+
+	return "", false
+}
+
+// DownloadByHost handles requests to the virtual host of the container
+// serving objects by FilePath attribute equal to the request path.
+func (d *Downloader) DownloadByHost(c *fasthttp.RequestCtx) {
+	d.byHost(c, request.receiveFile)
+}
+
+// HeadByHost handles head requests to the virtual host of the container.
+func (d *Downloader) HeadByHost(c *fasthttp.RequestCtx) {
+	d.byHost(c, request.headObject)
+}
+
+// byHost is a wrapper similar to byAttribute, container ID or name is
+// expected to be already set from the Host header.
+func (d *Downloader) byHost(c *fasthttp.RequestCtx, f func(request, *pool.Pool, oid.Address)) {
+	var (
+		scid, _  = c.UserValue("cid").(string)
+		filePath = hostFilePath(c)
+		log      = d.log.With(zap.String("cid", scid), zap.String("path", filePath), zap.ByteString("host", c.Host()))
+	)
+
+	containerID, err := utils.GetContainerID(d.appCtx, scid, d.containerResolver)
+	if err != nil {
+		log.Error("wrong container id", zap.Error(err))
+		response.Error(c, "wrong container id", fasthttp.StatusBadRequest)
+		return
+	}
+
+	website, err := d.isWebsite(scid, containerID)
+	if err != nil {
+		log.Error("could not check website mode", zap.Error(err))
+		d.handleContainerErr(c, err)
+		return
+	}
+	if website {
+		d.serveWebsite(c, log, containerID, filePath, f)
+		return
+	}
+
+	objID, err := d.findObject(c, containerID, object.AttributeFilePath, strings.TrimPrefix(filePath, "/"))
+	if err != nil {
+		d.handleFindErr(c, log, err)
+		return
+	}
+
+	var addrObj oid.Address
+	addrObj.SetContainer(*containerID)
+	addrObj.SetObject(objID)
+
+	f(*d.newRequest(c, log), d.pool, addrObj)
+}
+
+// hostFilePath returns the path requested from the virtual host. Unlike
+// route parameters, the request path is already decoded by fasthttp, so
+// it mustn't be decoded again.
+func hostFilePath(c *fasthttp.RequestCtx) string {
+	return string(c.Path())
+}
@@ -0,0 +1,50 @@
+package downloader
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+)
+
+func TestVirtualHostContainer(t *testing.T) {
+	var s Settings
+	s.SetVirtualHosts(map[string]string{
+		"Docs.Example.com": "BJeErH9MWmf52VsR1mLWKkgF3pRm3FkubYxM7TZkBP4K",
+	}, []string{"gw.example.com", ".neofs.io."})
+
+	for _, tc := range []struct {
+		host     string
+		expected string
+		ok       bool
+	}{
+		{host: "docs.example.com", expected: "BJeErH9MWmf52VsR1mLWKkgF3pRm3FkubYxM7TZkBP4K", ok: true},
+		{host: "docs.example.com:8080", expected: "BJeErH9MWmf52VsR1mLWKkgF3pRm3FkubYxM7TZkBP4K", ok: true},
+		{host: "mycontainer.gw.example.com", expected: "mycontainer", ok: true},
+		{host: "MyContainer.GW.example.com.", expected: "mycontainer", ok: true},
+		{host: "site.neofs.io:443", expected: "site", ok: true},
+		{host: "gw.example.com"},
+		{host: "a.b.gw.example.com"},
+		{host: "xgw.example.com"},
+		{host: "localhost:8080"},
+	} {
+		cnr, ok := s.VirtualHostContainer(tc.host)
+		require.Equal(t, tc.ok, ok, tc.host)
+		require.Equal(t, tc.expected, cnr, tc.host)
+	}
+}
+
+func TestHostFilePath(t *testing.T) {
+	for uri, expected := range map[string]string{
+		"/dir/file.txt":      "/dir/file.txt",
+		"/a+b.txt":           "/a+b.txt",
+		"/100%25.txt":        "/100%.txt",
+		"/%2541.txt":         "/%41.txt",
+		"/%D1%84%20name.txt": "/ф name.txt",
+		"/file.txt?x=%25":    "/file.txt",
+	} {
+		var c fasthttp.RequestCtx
+		c.Request.SetRequestURI(uri)
+		require.Equal(t, expected, hostFilePath(&c), uri)
+	}
+}
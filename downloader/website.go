@@ -0,0 +1,218 @@
+package downloader
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+	"time"
+
+	lru "github.com/hashicorp/golang-lru"
+	"github.com/nspcc-dev/neofs-http-gw/response"
+	"github.com/nspcc-dev/neofs-http-gw/utils"
+	"github.com/nspcc-dev/neofs-sdk-go/client"
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	"github.com/nspcc-dev/neofs-sdk-go/pool"
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+)
+
+// AttributeWebsite is a container attribute enabling website mode for the
+// container if it's set to "true".
+const AttributeWebsite = "Website"
+
+type (
+	// websiteCache is an LRU cache of the website mode set by container
+	// attribute, so the container isn't requested on every request.
+	websiteCache struct {
+		ttl time.Duration
+		lru *lru.Cache
+	}
+
+	websiteCacheEntry struct {
+		enabled bool
+		expires time.Time
+	}
+)
+
+// newWebsiteCache creates the cache. Returns nil if the cache is disabled.
+func newWebsiteCache(size int, ttl time.Duration) *websiteCache {
+	if size <= 0 || ttl <= 0 {
+		return nil
+	}
+
+	// lru.New fails only if the size isn't positive
+	l, _ := lru.New(size)
+	return &websiteCache{ttl: ttl, lru: l}
+}
+
+// get returns the cached website mode of the container. The second return
+// value is false if there is no actual value in the cache.
+func (c *websiteCache) get(cnrID cid.ID) (bool, bool) {
+	if c == nil {
+		return false, false
+	}
+
+	val, ok := c.lru.Get(cnrID)
+	if !ok {
+		return false, false
+	}
+
+	entry := val.(websiteCacheEntry)
+	if time.Now().After(entry.expires) {
+		c.lru.Remove(cnrID)
+		return false, false
+	}
+
+	return entry.enabled, true
+}
+
+func (c *websiteCache) put(cnrID cid.ID, enabled bool) {
+	if c == nil {
+		return
+	}
+
+	c.lru.Add(cnrID, websiteCacheEntry{
+		enabled: enabled,
+		expires: time.Now().Add(c.ttl),
+	})
+}
+
+// SetWebsiteCache recreates the cache of website mode set by container
+// attribute, so it's invalidated. The cache is disabled if the size or
+// the lifetime of entries isn't positive.
+func (s *Settings) SetWebsiteCache(size int, ttl time.Duration) {
+	c := newWebsiteCache(size, ttl)
+
+	s.mu.Lock()
+	s.websiteCache = c
+	s.mu.Unlock()
+}
+
+func (s *Settings) getWebsiteCache() *websiteCache {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.websiteCache
+}
+
+// DownloadWebsite handles website requests serving objects by FilePath attribute.
+func (d *Downloader) DownloadWebsite(c *fasthttp.RequestCtx) {
+	d.website(c, request.receiveFile)
+}
+
+// HeadWebsite handles website head requests.
+func (d *Downloader) HeadWebsite(c *fasthttp.RequestCtx) {
+	d.website(c, request.headObject)
+}
+
+// website is a wrapper similar to byAttribute. It maps the request path to
+// FilePath attribute, serves index document for directory-like paths and
+// error document if nothing is found.
+func (d *Downloader) website(c *fasthttp.RequestCtx, f func(request, *pool.Pool, oid.Address)) {
+	var (
+		scid, _     = c.UserValue("cid").(string)
+		filePath, _ = url.QueryUnescape(c.UserValue("path").(string))
+		log         = d.log.With(zap.String("cid", scid), zap.String("path", filePath))
+	)
+
+	containerID, err := utils.GetContainerID(d.appCtx, scid, d.containerResolver)
+	if err != nil {
+		log.Error("wrong container id", zap.Error(err))
+		response.Error(c, "wrong container id", fasthttp.StatusBadRequest)
+		return
+	}
+
+	enabled, err := d.isWebsite(scid, containerID)
+	if err != nil {
+		log.Error("could not check website mode", zap.Error(err))
+		d.handleContainerErr(c, err)
+		return
+	}
+	if !enabled {
+		log.Error("website mode is disabled for container")
+		response.Error(c, "website mode is disabled for container", fasthttp.StatusNotFound)
+		return
+	}
+
+	d.serveWebsite(c, log, containerID, filePath, f)
+}
+
+// serveWebsite serves the website page of the container by the path.
+func (d *Downloader) serveWebsite(c *fasthttp.RequestCtx, log *zap.Logger, containerID *cid.ID,
+	filePath string, f func(request, *pool.Pool, oid.Address)) {
+	var (
+		notFound bool
+		index    = d.settings.WebsiteIndexDocument()
+	)
+
+	objID, err := d.findObject(c, containerID, object.AttributeFilePath, websitePath(filePath, index))
+	if errors.Is(err, errObjectNotFound) && !isDirectoryPath(filePath) && index != "" {
+		// the path can be a directory without trailing slash
+		objID, err = d.findObject(c, containerID, object.AttributeFilePath, websitePath(filePath+"/", index))
+	}
+	if errDoc := d.settings.WebsiteErrorDocument(); errors.Is(err, errObjectNotFound) && errDoc != "" {
+		notFound = true
+		objID, err = d.findObject(c, containerID, object.AttributeFilePath, errDoc)
+	}
+	if err != nil {
+		d.handleFindErr(c, log, err)
+		return
+	}
+
+	var addrObj oid.Address
+	addrObj.SetContainer(*containerID)
+	addrObj.SetObject(objID)
+
+	f(*d.newRequest(c, log), d.pool, addrObj)
+
+	if notFound && c.Response.StatusCode() == fasthttp.StatusOK {
+		c.SetStatusCode(fasthttp.StatusNotFound)
+	}
+}
+
+// isWebsite checks if website mode is enabled for the container by settings
+// or by the container attribute. The attribute is cached.
+func (d *Downloader) isWebsite(scid string, cnrID *cid.ID) (bool, error) {
+	if d.settings.WebsiteEnabled() || d.settings.WebsiteContainer(scid) || d.settings.WebsiteContainer(cnrID.EncodeToString()) {
+		return true, nil
+	}
+
+	cache := d.settings.getWebsiteCache()
+	if enabled, ok := cache.get(*cnrID); ok {
+		return enabled, nil
+	}
+
+	cnr, err := d.getContainer(*cnrID)
+	if err != nil {
+		return false, err
+	}
+
+	enabled := cnr.Attribute(AttributeWebsite) == "true"
+	cache.put(*cnrID, enabled)
+
+	return enabled, nil
+}
+
+// handleContainerErr responds with 404 if the container doesn't exist and
+// with 400 otherwise.
+func (d *Downloader) handleContainerErr(c *fasthttp.RequestCtx, err error) {
+	if client.IsErrContainerNotFound(err) {
+		response.Error(c, "Not Found", fasthttp.StatusNotFound)
+		return
+	}
+	response.Error(c, "could not get container: "+err.Error(), fasthttp.StatusBadRequest)
+}
+
+// websitePath converts request path to the FilePath attribute value.
+func websitePath(filePath, index string) string {
+	filePath = strings.TrimPrefix(filePath, "/")
+	if isDirectoryPath(filePath) {
+		filePath += index
+	}
+	return filePath
+}
+
+func isDirectoryPath(filePath string) bool {
+	return filePath == "" || strings.HasSuffix(filePath, "/")
+}
@@ -0,0 +1,84 @@
+package downloader
+
+import (
+	"testing"
+	"time"
+
+	cidtest "github.com/nspcc-dev/neofs-sdk-go/container/id/test"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebsitePath(t *testing.T) {
+	const index = "index.html"
+
+	for _, tc := range []struct {
+		path     string
+		expected string
+	}{
+		{path: "", expected: "index.html"},
+		{path: "/", expected: "index.html"},
+		{path: "docs/", expected: "docs/index.html"},
+		{path: "/docs/page.html", expected: "docs/page.html"},
+		{path: "page.html", expected: "page.html"},
+	} {
+		require.Equal(t, tc.expected, websitePath(tc.path, index), tc.path)
+	}
+}
+
+func TestWebsiteSettings(t *testing.T) {
+	var s Settings
+	require.False(t, s.WebsiteContainer("site"))
+
+	s.SetWebsiteContainers([]string{"site", "BJeErH9MWmf52VsR1mLWKkgF3pRm3FkubYxM7TZkBP4K"})
+	require.True(t, s.WebsiteContainer("site"))
+	require.True(t, s.WebsiteContainer("BJeErH9MWmf52VsR1mLWKkgF3pRm3FkubYxM7TZkBP4K"))
+	require.False(t, s.WebsiteContainer("other"))
+
+	s.SetWebsiteContainers(nil)
+	require.False(t, s.WebsiteContainer("site"))
+}
+
+func TestWebsiteCache(t *testing.T) {
+	var (
+		s          Settings
+		enabledID  = cidtest.ID()
+		disabledID = cidtest.ID()
+	)
+
+	// disabled by default
+	s.getWebsiteCache().put(enabledID, true)
+	_, ok := s.getWebsiteCache().get(enabledID)
+	require.False(t, ok)
+
+	s.SetWebsiteCache(10, time.Minute)
+	cache := s.getWebsiteCache()
+	cache.put(enabledID, true)
+	cache.put(disabledID, false)
+
+	enabled, ok := cache.get(enabledID)
+	require.True(t, ok)
+	require.True(t, enabled)
+
+	enabled, ok = cache.get(disabledID)
+	require.True(t, ok)
+	require.False(t, enabled)
+
+	_, ok = cache.get(cidtest.ID())
+	require.False(t, ok)
+
+	// settings update invalidates the cache
+	s.SetWebsiteCache(10, time.Minute)
+	_, ok = s.getWebsiteCache().get(enabledID)
+	require.False(t, ok)
+
+	t.Run("expired", func(t *testing.T) {
+		cache := newWebsiteCache(10, time.Millisecond)
+		cache.put(enabledID, true)
+		time.Sleep(2 * time.Millisecond)
+		_, ok := cache.get(enabledID)
+		require.False(t, ok)
+	})
+
+	require.Nil(t, newWebsiteCache(0, time.Minute))
+	require.Nil(t, newWebsiteCache(10, 0))
+}
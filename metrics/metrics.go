@@ -2,6 +2,7 @@ package metrics
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/nspcc-dev/neofs-sdk-go/pool"
 	"github.com/prometheus/client_golang/prometheus"
@@ -10,9 +11,11 @@ import (
 )
 
 const (
-	namespace      = "neofs_http_gw"
-	stateSubsystem = "state"
-	poolSubsystem  = "pool"
+	namespace         = "neofs_http_gw"
+	stateSubsystem    = "state"
+	poolSubsystem     = "pool"
+	resolverSubsystem = "resolver"
+	archiveSubsystem  = "archive"
 
 	methodGetBalance       = "get_balance"
 	methodPutContainer     = "put_container"
@@ -34,12 +37,26 @@ const (
 type GateMetrics struct {
 	stateMetrics
 	poolMetricsCollector
+	resolverMetrics
+	archiveMetrics
 }
 
 type stateMetrics struct {
 	healthCheck prometheus.Gauge
 }
 
+type resolverMetrics struct {
+	cacheHits   prometheus.Counter
+	cacheMisses prometheus.Counter
+}
+
+type archiveMetrics struct {
+	objects  prometheus.Counter
+	bytes    prometheus.Counter
+	failures prometheus.Counter
+	duration prometheus.Histogram
+}
+
 type poolMetricsCollector struct {
 	pool                *pool.Pool
 	overallErrors       prometheus.Gauge
@@ -57,15 +74,25 @@ func NewGateMetrics(p *pool.Pool) *GateMetrics {
 	poolMetric := newPoolMetricsCollector(p)
 	poolMetric.register()
 
+	resolverMetric := newResolverMetrics()
+	resolverMetric.register()
+
+	archiveMetric := newArchiveMetrics()
+	archiveMetric.register()
+
 	return &GateMetrics{
 		stateMetrics:         *stateMetric,
 		poolMetricsCollector: *poolMetric,
+		resolverMetrics:      *resolverMetric,
+		archiveMetrics:       *archiveMetric,
 	}
 }
 
 func (g *GateMetrics) Unregister() {
 	g.stateMetrics.unregister()
 	prometheus.Unregister(&g.poolMetricsCollector)
+	g.resolverMetrics.unregister()
+	g.archiveMetrics.unregister()
 }
 
 func newStateMetrics() *stateMetrics {
@@ -91,6 +118,98 @@ func (m stateMetrics) SetHealth(s int32) {
 	m.healthCheck.Set(float64(s))
 }
 
+func newResolverMetrics() *resolverMetrics {
+	return &resolverMetrics{
+		cacheHits: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: resolverSubsystem,
+			Name:      "cache_hits_total",
+			Help:      "Total number of container names resolved from cache",
+		}),
+		cacheMisses: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: resolverSubsystem,
+			Name:      "cache_misses_total",
+			Help:      "Total number of container names missed in cache",
+		}),
+	}
+}
+
+func (m resolverMetrics) register() {
+	prometheus.MustRegister(m.cacheHits)
+	prometheus.MustRegister(m.cacheMisses)
+}
+
+func (m resolverMetrics) unregister() {
+	prometheus.Unregister(m.cacheHits)
+	prometheus.Unregister(m.cacheMisses)
+}
+
+func (m resolverMetrics) ResolverCacheHit() {
+	m.cacheHits.Inc()
+}
+
+func (m resolverMetrics) ResolverCacheMiss() {
+	m.cacheMisses.Inc()
+}
+
+func newArchiveMetrics() *archiveMetrics {
+	return &archiveMetrics{
+		objects: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: archiveSubsystem,
+			Name:      "objects_total",
+			Help:      "Total number of objects added to archives",
+		}),
+		bytes: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: archiveSubsystem,
+			Name:      "payload_bytes_total",
+			Help:      "Total size of object payloads added to archives",
+		}),
+		failures: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: archiveSubsystem,
+			Name:      "failed_objects_total",
+			Help:      "Total number of objects failed to be added to archives",
+		}),
+		duration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: archiveSubsystem,
+			Name:      "duration_seconds",
+			Help:      "Duration of archive streaming",
+			Buckets:   prometheus.ExponentialBuckets(0.01, 4, 10),
+		}),
+	}
+}
+
+func (m archiveMetrics) register() {
+	prometheus.MustRegister(m.objects)
+	prometheus.MustRegister(m.bytes)
+	prometheus.MustRegister(m.failures)
+	prometheus.MustRegister(m.duration)
+}
+
+func (m archiveMetrics) unregister() {
+	prometheus.Unregister(m.objects)
+	prometheus.Unregister(m.bytes)
+	prometheus.Unregister(m.failures)
+	prometheus.Unregister(m.duration)
+}
+
+func (m archiveMetrics) AddArchiveObject(size uint64) {
+	m.objects.Inc()
+	m.bytes.Add(float64(size))
+}
+
+func (m archiveMetrics) AddArchiveFailure() {
+	m.failures.Inc()
+}
+
+func (m archiveMetrics) ObserveArchiveDuration(d time.Duration) {
+	m.duration.Observe(d.Seconds())
+}
+
 func newPoolMetricsCollector(p *pool.Pool) *poolMetricsCollector {
 	overallErrors := prometheus.NewGauge(
 		prometheus.GaugeOpts{
@@ -0,0 +1,113 @@
+package resolver
+
+import (
+	"fmt"
+	"time"
+
+	lru "github.com/hashicorp/golang-lru"
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+)
+
+// CacheConfig contains parameters of the container name resolution cache.
+// The cache is disabled if Size or both TTLs are not positive.
+type CacheConfig struct {
+	// Size is the maximum number of cached names.
+	Size int
+	// TTL is the lifetime of successfully resolved names.
+	TTL time.Duration
+	// NegativeTTL is the lifetime of names which were failed to resolve.
+	NegativeTTL time.Duration
+}
+
+// CacheMetrics collects statistics of the resolution cache.
+type CacheMetrics interface {
+	ResolverCacheHit()
+	ResolverCacheMiss()
+}
+
+type (
+	// cache is an LRU cache of resolved container names with expiration.
+	cache struct {
+		cfg     CacheConfig
+		lru     *lru.Cache
+		metrics CacheMetrics
+	}
+
+	cacheEntry struct {
+		cnrID   *cid.ID
+		err     error
+		expires time.Time
+	}
+)
+
+// newCache creates the cache. Returns nil if the cache is disabled.
+func newCache(cfg CacheConfig, metrics CacheMetrics) (*cache, error) {
+	if cfg.Size <= 0 || (cfg.TTL <= 0 && cfg.NegativeTTL <= 0) {
+		return nil, nil
+	}
+
+	l, err := lru.New(cfg.Size)
+	if err != nil {
+		return nil, fmt.Errorf("could not create lru cache: %w", err)
+	}
+
+	return &cache{
+		cfg:     cfg,
+		lru:     l,
+		metrics: metrics,
+	}, nil
+}
+
+// get returns cached resolution result. The second return value is false
+// if there is no actual result in the cache.
+func (c *cache) get(name string) (cacheEntry, bool) {
+	if c == nil {
+		return cacheEntry{}, false
+	}
+
+	val, ok := c.lru.Get(name)
+	if ok {
+		entry := val.(cacheEntry)
+		if time.Now().Before(entry.expires) {
+			c.hit()
+			return entry, true
+		}
+		c.lru.Remove(name)
+	}
+
+	c.miss()
+	return cacheEntry{}, false
+}
+
+// put stores resolution result in the cache.
+func (c *cache) put(name string, cnrID *cid.ID, err error) {
+	if c == nil {
+		return
+	}
+
+	ttl := c.cfg.TTL
+	if err != nil {
+		ttl = c.cfg.NegativeTTL
+	}
+	if ttl <= 0 {
+		return
+	}
+
+	c.lru.Add(name, cacheEntry{
+		cnrID:   cnrID,
+		err:     err,
+		expires: time.Now().Add(ttl),
+	})
+}
+
+func (c *cache) hit() {
+	if c.metrics != nil {
+		c.metrics.ResolverCacheHit()
+	}
+}
+
+func (c *cache) miss() {
+	if c.metrics != nil {
+		c.metrics.ResolverCacheMiss()
+	}
+}
@@ -0,0 +1,91 @@
+package resolver
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	cidtest "github.com/nspcc-dev/neofs-sdk-go/container/id/test"
+	"github.com/stretchr/testify/require"
+)
+
+type cacheMetricsMock struct {
+	hits, misses int
+}
+
+func (m *cacheMetricsMock) ResolverCacheHit() {
+	m.hits++
+}
+
+func (m *cacheMetricsMock) ResolverCacheMiss() {
+	m.misses++
+}
+
+func TestResolverCache(t *testing.T) {
+	ctx := context.Background()
+	cnrID := cidtest.ID()
+	errResolve := errors.New("not found")
+
+	var calls int
+	r := &Resolver{Name: "test"}
+	r.SetResolveFunc(func(_ context.Context, name string) (*cid.ID, error) {
+		calls++
+		if name == "known" {
+			return &cnrID, nil
+		}
+		return nil, errResolve
+	})
+
+	metrics := new(cacheMetricsMock)
+	c, err := newCache(CacheConfig{Size: 10, TTL: time.Minute, NegativeTTL: time.Minute}, metrics)
+	require.NoError(t, err)
+
+	cnrResolver := &ContainerResolver{resolvers: []*Resolver{r}, cache: c}
+
+	for i := 0; i < 3; i++ {
+		res, err := cnrResolver.Resolve(ctx, "known")
+		require.NoError(t, err)
+		require.Equal(t, cnrID, *res)
+
+		_, err = cnrResolver.Resolve(ctx, "unknown")
+		require.ErrorIs(t, err, errResolve)
+	}
+
+	require.Equal(t, 2, calls)
+	require.Equal(t, 2, metrics.misses)
+	require.Equal(t, 4, metrics.hits)
+
+	t.Run("expiration", func(t *testing.T) {
+		c, err := newCache(CacheConfig{Size: 10, TTL: time.Minute, NegativeTTL: time.Nanosecond}, nil)
+		require.NoError(t, err)
+
+		calls = 0
+		cnrResolver := &ContainerResolver{resolvers: []*Resolver{r}, cache: c}
+
+		for i := 0; i < 3; i++ {
+			_, err = cnrResolver.Resolve(ctx, "unknown")
+			require.ErrorIs(t, err, errResolve)
+			time.Sleep(time.Millisecond)
+		}
+
+		require.Equal(t, 3, calls)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		c, err := newCache(CacheConfig{}, nil)
+		require.NoError(t, err)
+		require.Nil(t, c)
+
+		calls = 0
+		cnrResolver := &ContainerResolver{resolvers: []*Resolver{r}}
+
+		for i := 0; i < 3; i++ {
+			_, err = cnrResolver.Resolve(ctx, "known")
+			require.NoError(t, err)
+		}
+
+		require.Equal(t, 3, calls)
+	})
+}
@@ -12,8 +12,9 @@ import (
 )
 
 const (
-	NNSResolver = "nns"
-	DNSResolver = "dns"
+	NNSResolver    = "nns"
+	DNSResolver    = "dns"
+	StaticResolver = "static"
 )
 
 // ErrNoResolvers returns when trying to resolve container without any resolver.
@@ -31,11 +32,15 @@ type NeoFS interface {
 type Config struct {
 	NeoFS      NeoFS
 	RPCAddress string
+	Static     map[string]string
+	Cache      CacheConfig
+	Metrics    CacheMetrics
 }
 
 type ContainerResolver struct {
 	mu        sync.RWMutex
 	resolvers []*Resolver
+	cache     *cache
 }
 
 type Resolver struct {
@@ -57,8 +62,14 @@ func NewContainerResolver(resolverNames []string, cfg *Config) (*ContainerResolv
 		return nil, err
 	}
 
+	c, err := newCache(cfg.Cache, cfg.Metrics)
+	if err != nil {
+		return nil, err
+	}
+
 	return &ContainerResolver{
 		resolvers: resolvers,
+		cache:     c,
 	}, nil
 }
 
@@ -79,6 +90,20 @@ func (r *ContainerResolver) Resolve(ctx context.Context, cnrName string) (*cid.I
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
+	if entry, ok := r.cache.get(cnrName); ok {
+		return entry.cnrID, entry.err
+	}
+
+	cnrID, err := r.resolve(ctx, cnrName)
+	// don't cache failures caused by the request itself
+	if ctx.Err() == nil && !errors.Is(err, ErrNoResolvers) {
+		r.cache.put(cnrName, cnrID, err)
+	}
+
+	return cnrID, err
+}
+
+func (r *ContainerResolver) resolve(ctx context.Context, cnrName string) (*cid.ID, error) {
 	var err error
 	for _, resolver := range r.resolvers {
 		cnrID, resolverErr := resolver.Resolve(ctx, cnrName)
@@ -101,12 +126,22 @@ func (r *ContainerResolver) Resolve(ctx context.Context, cnrName string) (*cid.I
 	return nil, ErrNoResolvers
 }
 
+// UpdateResolvers updates resolvers if their order has been changed. Static
+// resolver is recreated anyway to apply new mappings. The cache of resolved
+// names is recreated too, so it's invalidated.
 func (r *ContainerResolver) UpdateResolvers(resolverNames []string, cfg *Config) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
+	c, err := newCache(cfg.Cache, cfg.Metrics)
+	if err != nil {
+		return err
+	}
+
+	r.cache = c
+
 	if r.equals(resolverNames) {
-		return nil
+		return r.updateStatic(cfg)
 	}
 
 	resolvers, err := createResolvers(resolverNames, cfg)
@@ -119,6 +154,22 @@ func (r *ContainerResolver) UpdateResolvers(resolverNames []string, cfg *Config)
 	return nil
 }
 
+func (r *ContainerResolver) updateStatic(cfg *Config) error {
+	for i := range r.resolvers {
+		if r.resolvers[i].Name != StaticResolver {
+			continue
+		}
+
+		static, err := NewStaticResolver(cfg.Static)
+		if err != nil {
+			return err
+		}
+		r.resolvers[i] = static
+	}
+
+	return nil
+}
+
 func (r *ContainerResolver) equals(resolverNames []string) bool {
 	if len(r.resolvers) != len(resolverNames) {
 		return false
@@ -138,6 +189,8 @@ func newResolver(name string, cfg *Config) (*Resolver, error) {
 		return NewDNSResolver(cfg.NeoFS)
 	case NNSResolver:
 		return NewNNSResolver(cfg.RPCAddress)
+	case StaticResolver:
+		return NewStaticResolver(cfg.Static)
 	default:
 		return nil, fmt.Errorf("unknown resolver: %s", name)
 	}
@@ -193,3 +246,29 @@ func NewNNSResolver(rpcAddress string) (*Resolver, error) {
 		resolve: resolveFunc,
 	}, nil
 }
+
+// NewStaticResolver creates resolver using predefined mapping of container
+// names to container IDs.
+func NewStaticResolver(mapping map[string]string) (*Resolver, error) {
+	containers := make(map[string]cid.ID, len(mapping))
+	for name, value := range mapping {
+		var cnrID cid.ID
+		if err := cnrID.DecodeString(value); err != nil {
+			return nil, fmt.Errorf("invalid container id '%s' for name '%s': %w", value, name, err)
+		}
+		containers[name] = cnrID
+	}
+
+	resolveFunc := func(_ context.Context, name string) (*cid.ID, error) {
+		cnrID, ok := containers[name]
+		if !ok {
+			return nil, fmt.Errorf("container '%s' not found in static mapping", name)
+		}
+		return &cnrID, nil
+	}
+
+	return &Resolver{
+		Name:    StaticResolver,
+		resolve: resolveFunc,
+	}, nil
+}
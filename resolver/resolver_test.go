@@ -0,0 +1,43 @@
+package resolver
+
+import (
+	"context"
+	"testing"
+
+	cidtest "github.com/nspcc-dev/neofs-sdk-go/container/id/test"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStaticResolver(t *testing.T) {
+	ctx := context.Background()
+	cnrID := cidtest.ID()
+
+	cfg := &Config{Static: map[string]string{"site": cnrID.EncodeToString()}}
+
+	cnrResolver, err := NewContainerResolver([]string{StaticResolver}, cfg)
+	require.NoError(t, err)
+
+	res, err := cnrResolver.Resolve(ctx, "site")
+	require.NoError(t, err)
+	require.Equal(t, cnrID, *res)
+
+	_, err = cnrResolver.Resolve(ctx, "unknown")
+	require.Error(t, err)
+
+	t.Run("reload mapping", func(t *testing.T) {
+		newCnrID := cidtest.ID()
+		cfg := &Config{Static: map[string]string{"site": newCnrID.EncodeToString()}}
+
+		err := cnrResolver.UpdateResolvers([]string{StaticResolver}, cfg)
+		require.NoError(t, err)
+
+		res, err := cnrResolver.Resolve(ctx, "site")
+		require.NoError(t, err)
+		require.Equal(t, newCnrID, *res)
+	})
+
+	t.Run("invalid id", func(t *testing.T) {
+		_, err := NewStaticResolver(map[string]string{"site": "invalid"})
+		require.Error(t, err)
+	})
+}
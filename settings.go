@@ -3,12 +3,14 @@ package main
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"runtime"
 	"sort"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/nspcc-dev/neofs-http-gw/downloader"
 	"github.com/nspcc-dev/neofs-http-gw/resolver"
 	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
@@ -27,6 +29,22 @@ const (
 
 	defaultPoolErrorThreshold uint32 = 100
 
+	defaultResolveCacheSize        = 1000
+	defaultResolveCacheTTL         = time.Minute
+	defaultResolveCacheNegativeTTL = 10 * time.Second
+
+	defaultWebsiteCacheSize = 1000
+	defaultWebsiteCacheTTL  = time.Minute
+
+	defaultZipWorkers      = 4
+	defaultZipMemoryBudget = 32 << 20
+
+	defaultResumableUploadMaxSize      = 4 << 30
+	defaultResumableUploadMaxTotalSize = 20 << 30
+	defaultResumableUploadLifetime     = 24 * time.Hour
+
+	defaultExtractMaxSize = 4 << 30
+
 	cfgServer      = "server"
 	cfgTLSEnabled  = "tls.enabled"
 	cfgTLSCertFile = "tls.cert_file"
@@ -63,6 +81,18 @@ const (
 
 	// Uploader Header.
 	cfgUploaderHeaderEnableDefaultTimestamp = "upload_header.use_default_timestamp"
+	cfgUploaderHeaderDetectContentType      = "upload_header.detect_content_type"
+
+	// Resumable upload.
+	cfgResumableUploadEnabled      = "resumable_upload.enabled"
+	cfgResumableUploadDir          = "resumable_upload.dir"
+	cfgResumableUploadMaxSize      = "resumable_upload.max_size"
+	cfgResumableUploadMaxTotalSize = "resumable_upload.max_total_size"
+	cfgResumableUploadLifetime     = "resumable_upload.lifetime"
+
+	// Archive extraction.
+	cfgExtractDir     = "extract.dir"
+	cfgExtractMaxSize = "extract.max_size"
 
 	// Peers.
 	cfgPeers = "peers"
@@ -71,10 +101,32 @@ const (
 	cfgRPCEndpoint = "rpc_endpoint"
 
 	// Resolving.
-	cfgResolveOrder = "resolve_order"
+	cfgResolveOrder            = "resolve_order"
+	cfgResolveStatic           = "resolve_static"
+	cfgResolveCacheSize        = "resolve_cache.size"
+	cfgResolveCacheTTL         = "resolve_cache.ttl"
+	cfgResolveCacheNegativeTTL = "resolve_cache.negative_ttl"
 
 	// Zip compression.
-	cfgZipCompression = "zip.compression"
+	cfgZipCompression  = "zip.compression"
+	cfgZipWorkers      = "zip.workers"
+	cfgZipMemoryBudget = "zip.memory_budget"
+	cfgZipOnError      = "zip.on_error"
+
+	// Object selection by attribute.
+	cfgGetByAttributeMultipleObjects = "get_by_attribute.multiple_objects"
+
+	// Website.
+	cfgWebsiteEnabled       = "website.enabled"
+	cfgWebsiteContainers    = "website.containers"
+	cfgWebsiteIndexDocument = "website.index_document"
+	cfgWebsiteErrorDocument = "website.error_document"
+	cfgWebsiteCacheSize     = "website.cache.size"
+	cfgWebsiteCacheTTL      = "website.cache.ttl"
+
+	// Virtual hosts.
+	cfgVirtualHostsDomains = "virtual_hosts.domains"
+	cfgVirtualHostsMap     = "virtual_hosts.map"
 
 	// Command line args.
 	cmdHelp          = "help"
@@ -135,6 +187,11 @@ func settings() *viper.Viper {
 	// pool:
 	v.SetDefault(cfgPoolErrorThreshold, defaultPoolErrorThreshold)
 
+	// resolve cache:
+	v.SetDefault(cfgResolveCacheSize, defaultResolveCacheSize)
+	v.SetDefault(cfgResolveCacheTTL, defaultResolveCacheTTL)
+	v.SetDefault(cfgResolveCacheNegativeTTL, defaultResolveCacheNegativeTTL)
+
 	// web-server:
 	v.SetDefault(cfgWebReadBufferSize, 4096)
 	v.SetDefault(cfgWebWriteBufferSize, 4096)
@@ -145,9 +202,33 @@ func settings() *viper.Viper {
 
 	// upload header
 	v.SetDefault(cfgUploaderHeaderEnableDefaultTimestamp, false)
+	v.SetDefault(cfgUploaderHeaderDetectContentType, false)
+
+	// resumable upload:
+	v.SetDefault(cfgResumableUploadEnabled, false)
+	v.SetDefault(cfgResumableUploadDir, filepath.Join(os.TempDir(), "neofs-http-gw-uploads"))
+	v.SetDefault(cfgResumableUploadMaxSize, defaultResumableUploadMaxSize)
+	v.SetDefault(cfgResumableUploadMaxTotalSize, defaultResumableUploadMaxTotalSize)
+	v.SetDefault(cfgResumableUploadLifetime, defaultResumableUploadLifetime)
+
+	// extract:
+	v.SetDefault(cfgExtractDir, os.TempDir())
+	v.SetDefault(cfgExtractMaxSize, defaultExtractMaxSize)
 
 	// zip:
 	v.SetDefault(cfgZipCompression, false)
+	v.SetDefault(cfgZipWorkers, defaultZipWorkers)
+	v.SetDefault(cfgZipMemoryBudget, defaultZipMemoryBudget)
+	v.SetDefault(cfgZipOnError, downloader.ArchiveErrorsSkip)
+
+	// get by attribute:
+	v.SetDefault(cfgGetByAttributeMultipleObjects, downloader.PolicyFirst)
+
+	// website:
+	v.SetDefault(cfgWebsiteEnabled, false)
+	v.SetDefault(cfgWebsiteIndexDocument, "index.html")
+	v.SetDefault(cfgWebsiteCacheSize, defaultWebsiteCacheSize)
+	v.SetDefault(cfgWebsiteCacheTTL, defaultWebsiteCacheTTL)
 
 	// metrics
 	v.SetDefault(cfgPprofAddress, "localhost:8083")
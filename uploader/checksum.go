@@ -0,0 +1,186 @@
+package uploader
+
+import (
+	"bytes"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"strings"
+)
+
+// Headers with the expected checksums of the payload.
+const (
+	hdrContentMD5     = "Content-MD5"
+	hdrDigest         = "Digest"
+	hdrChecksumSHA256 = "X-Checksum-Sha256"
+)
+
+// expectedChecksums are checksums of the payload provided by the client,
+// nil ones aren't checked.
+type expectedChecksums struct {
+	md5    []byte
+	sha256 []byte
+}
+
+func (e expectedChecksums) empty() bool {
+	return e.md5 == nil && e.sha256 == nil
+}
+
+// payloadChecksum is computed checksums of the stored payload in hex.
+type payloadChecksum struct {
+	MD5    string `json:"md5"`
+	SHA256 string `json:"sha256"`
+}
+
+// parseChecksumHeaders returns checksums from Content-MD5 (RFC 1864), Digest
+// (RFC 3230, md5 and sha-256 algorithms, others are ignored) and
+// X-Checksum-Sha256 (hex or base64 encoded) headers. Header values are
+// returned by the getter.
+func parseChecksumHeaders(get func(key string) string) (expectedChecksums, error) {
+	var res expectedChecksums
+
+	if val := get(hdrContentMD5); val != "" {
+		sum, err := decodeChecksum(val, md5.Size, false)
+		if err != nil {
+			return res, fmt.Errorf("invalid %s header: %w", hdrContentMD5, err)
+		}
+		res.md5 = sum
+	}
+
+	if val := get(hdrDigest); val != "" {
+		for _, digest := range strings.Split(val, ",") {
+			ind := strings.IndexByte(digest, '=')
+			if ind < 0 {
+				return res, fmt.Errorf("invalid %s header: %s", hdrDigest, digest)
+			}
+
+			var (
+				dst  *[]byte
+				size int
+			)
+			switch strings.ToLower(strings.TrimSpace(digest[:ind])) {
+			case "md5":
+				dst, size = &res.md5, md5.Size
+			case "sha-256":
+				dst, size = &res.sha256, sha256.Size
+			default:
+				continue
+			}
+
+			sum, err := decodeChecksum(strings.TrimSpace(digest[ind+1:]), size, false)
+			if err != nil {
+				return res, fmt.Errorf("invalid %s header: %w", hdrDigest, err)
+			}
+			if err = setChecksum(dst, sum); err != nil {
+				return res, err
+			}
+		}
+	}
+
+	if val := get(hdrChecksumSHA256); val != "" {
+		sum, err := decodeChecksum(val, sha256.Size, true)
+		if err != nil {
+			return res, fmt.Errorf("invalid %s header: %w", hdrChecksumSHA256, err)
+		}
+		if err = setChecksum(&res.sha256, sum); err != nil {
+			return res, err
+		}
+	}
+
+	return res, nil
+}
+
+// decodeChecksum decodes base64 (or hex if allowed) encoded checksum
+// of the specified size.
+func decodeChecksum(val string, size int, allowHex bool) ([]byte, error) {
+	var (
+		sum []byte
+		err error
+	)
+	if allowHex && len(val) == hex.EncodedLen(size) {
+		sum, err = hex.DecodeString(val)
+	} else {
+		sum, err = base64.StdEncoding.DecodeString(val)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(sum) != size {
+		return nil, fmt.Errorf("invalid checksum size %d", len(sum))
+	}
+	return sum, nil
+}
+
+// setChecksum sets the checksum if it's not set by another header yet.
+func setChecksum(dst *[]byte, sum []byte) error {
+	if *dst != nil && !bytes.Equal(*dst, sum) {
+		return fmt.Errorf("conflicting checksums in headers")
+	}
+	*dst = sum
+	return nil
+}
+
+// errChecksumMismatch is returned if the computed checksum of the payload
+// differs from the expected one.
+var errChecksumMismatch = errors.New("checksum mismatch")
+
+// checksumReader computes checksums of the payload while it's read. They are
+// verified after the payload is read to the end, so reading never fails
+// because of mismatch and the object is stored (and removed then).
+type checksumReader struct {
+	r        io.Reader
+	md5      hash.Hash
+	sha256   hash.Hash
+	n        uint64
+	expected expectedChecksums
+}
+
+func newChecksumReader(r io.Reader, expected expectedChecksums) *checksumReader {
+	return &checksumReader{
+		r:        r,
+		md5:      md5.New(),
+		sha256:   sha256.New(),
+		expected: expected,
+	}
+}
+
+func (r *checksumReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.md5.Write(p[:n])
+	r.sha256.Write(p[:n])
+	r.n += uint64(n)
+	return n, err
+}
+
+// verify compares checksums of the payload read so far with the expected ones.
+func (r *checksumReader) verify() error {
+	if r.expected.md5 != nil {
+		if sum := r.md5.Sum(nil); !bytes.Equal(sum, r.expected.md5) {
+			return fmt.Errorf("MD5 %w: expected %x, computed %x", errChecksumMismatch, r.expected.md5, sum)
+		}
+	}
+	if r.expected.sha256 != nil {
+		if sum := r.sha256.Sum(nil); !bytes.Equal(sum, r.expected.sha256) {
+			return fmt.Errorf("SHA-256 %w: expected %x, computed %x", errChecksumMismatch, r.expected.sha256, sum)
+		}
+	}
+	return nil
+}
+
+// checksum returns checksums of the payload read so far.
+func (r *checksumReader) checksum() *payloadChecksum {
+	return &payloadChecksum{
+		MD5:    hex.EncodeToString(r.md5.Sum(nil)),
+		SHA256: hex.EncodeToString(r.sha256.Sum(nil)),
+	}
+}
+
+// size returns the size of the payload read so far.
+func (r *checksumReader) size() uint64 {
+	return r.n
+}
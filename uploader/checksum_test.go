@@ -0,0 +1,100 @@
+package uploader
+
+import (
+	"encoding/hex"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Checksums of "hello".
+const (
+	helloMD5          = "5d41402abc4b2a76b9719d911017c592"
+	helloMD5Base64    = "XUFAKrxLKna5cZ2REBfFkg=="
+	helloSHA256       = "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"
+	helloSHA256Base64 = "LPJNul+wow4m6DsqxbninhsWHlwfp0JecwQzYpOLmCQ="
+)
+
+func TestParseChecksumHeaders(t *testing.T) {
+	parse := func(headers map[string]string) (expectedChecksums, error) {
+		return parseChecksumHeaders(func(key string) string {
+			return headers[key]
+		})
+	}
+
+	t.Run("no headers", func(t *testing.T) {
+		res, err := parse(nil)
+		require.NoError(t, err)
+		require.Nil(t, res.md5)
+		require.Nil(t, res.sha256)
+	})
+
+	t.Run("all headers", func(t *testing.T) {
+		res, err := parse(map[string]string{
+			hdrContentMD5:     helloMD5Base64,
+			hdrDigest:         "SHA-256=" + helloSHA256Base64 + ", md5=" + helloMD5Base64 + ", unixsum=30637",
+			hdrChecksumSHA256: helloSHA256,
+		})
+		require.NoError(t, err)
+		require.Equal(t, helloMD5, hex.EncodeToString(res.md5))
+		require.Equal(t, helloSHA256, hex.EncodeToString(res.sha256))
+	})
+
+	t.Run("base64 sha256", func(t *testing.T) {
+		res, err := parse(map[string]string{hdrChecksumSHA256: helloSHA256Base64})
+		require.NoError(t, err)
+		require.Equal(t, helloSHA256, hex.EncodeToString(res.sha256))
+	})
+
+	for name, headers := range map[string]map[string]string{
+		"invalid base64":  {hdrContentMD5: "not base64"},
+		"invalid size":    {hdrContentMD5: helloSHA256Base64},
+		"hex content md5": {hdrContentMD5: helloMD5},
+		"invalid digest":  {hdrDigest: "md5"},
+		"conflicting sha": {hdrDigest: "sha-256=" + helloSHA256Base64, hdrChecksumSHA256: strings.Repeat("0", 64)},
+		"conflicting md5": {hdrContentMD5: helloMD5Base64, hdrDigest: "md5=AAAAAAAAAAAAAAAAAAAAAA=="},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := parse(headers)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestChecksumReader(t *testing.T) {
+	read := func(expected map[string]string) (*checksumReader, error) {
+		sums, err := parseChecksumHeaders(func(key string) string {
+			return expected[key]
+		})
+		require.NoError(t, err)
+
+		r := newChecksumReader(strings.NewReader("hello"), sums)
+		// mismatch doesn't fail reading, it's checked after the put
+		_, err = io.ReadAll(r)
+		require.NoError(t, err)
+		return r, r.verify()
+	}
+
+	t.Run("no expected checksums", func(t *testing.T) {
+		r, err := read(nil)
+		require.NoError(t, err)
+		require.Equal(t, &payloadChecksum{MD5: helloMD5, SHA256: helloSHA256}, r.checksum())
+	})
+
+	t.Run("valid checksums", func(t *testing.T) {
+		_, err := read(map[string]string{hdrContentMD5: helloMD5Base64, hdrChecksumSHA256: helloSHA256})
+		require.NoError(t, err)
+	})
+
+	t.Run("md5 mismatch", func(t *testing.T) {
+		_, err := read(map[string]string{hdrContentMD5: "AAAAAAAAAAAAAAAAAAAAAA=="})
+		require.ErrorIs(t, err, errChecksumMismatch)
+	})
+
+	t.Run("sha256 mismatch", func(t *testing.T) {
+		_, err := read(map[string]string{hdrChecksumSHA256: strings.Repeat("0", 64)})
+		require.ErrorIs(t, err, errChecksumMismatch)
+	})
+}
@@ -0,0 +1,87 @@
+package uploader
+
+import (
+	"bytes"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+)
+
+// max bytes needed to detect content type according to http.DetectContentType docs.
+const sizeToDetectType = 512
+
+// withContentType adds ContentType attribute if detection is enabled by
+// settings and the attribute isn't set from header. The declared content
+// type is used if it's specific, otherwise the type is detected by FileName
+// extension or the first bytes of the payload. The returned reader must be
+// used instead of the payload.
+func (u *Uploader) withContentType(attributes []object.Attribute, declared string, payload io.Reader) ([]object.Attribute, io.Reader, error) {
+	if !u.settings.DetectContentType() {
+		return attributes, payload, nil
+	}
+
+	var filename string
+	for _, attr := range attributes {
+		switch attr.Key() {
+		case object.AttributeContentType:
+			return attributes, payload, nil
+		case object.AttributeFileName:
+			filename = attr.Value()
+		}
+	}
+
+	contentType, payload, err := detectContentType(declared, filename, payload)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	attr := object.NewAttribute()
+	attr.SetKey(object.AttributeContentType)
+	attr.SetValue(contentType)
+
+	return append(attributes, *attr), payload, nil
+}
+
+// detectContentType returns the declared content type unless it's generic,
+// the type registered for the file extension or the type detected by
+// http.DetectContentType.
+func detectContentType(declared, filename string, payload io.Reader) (string, io.Reader, error) {
+	if declared != "" && !isGenericContentType(declared) {
+		return declared, payload, nil
+	}
+
+	if ext := path.Ext(filename); ext != "" {
+		if contentType := mime.TypeByExtension(ext); contentType != "" {
+			return contentType, payload, nil
+		}
+	}
+
+	buf := make([]byte, sizeToDetectType)
+	n, err := io.ReadFull(payload, buf)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return "", nil, err
+	}
+	buf = buf[:n]
+
+	return http.DetectContentType(buf), io.MultiReader(bytes.NewReader(buf), payload), nil
+}
+
+// isGenericContentType checks if the content type is set by clients when
+// the actual one is unknown.
+func isGenericContentType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return true
+	}
+
+	switch strings.ToLower(mediaType) {
+	case "application/octet-stream", "application/x-www-form-urlencoded":
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,97 @@
+package uploader
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectContentType(t *testing.T) {
+	html := "<!DOCTYPE html><html><body>hello</body></html>"
+
+	for _, tc := range []struct {
+		name     string
+		declared string
+		filename string
+		payload  string
+		expected string
+	}{
+		{name: "declared", declared: "image/svg+xml", filename: "file.txt", payload: html, expected: "image/svg+xml"},
+		{name: "declared with params", declared: "text/plain; charset=utf-8", payload: html, expected: "text/plain; charset=utf-8"},
+		{name: "generic declared", declared: "application/octet-stream", filename: "file.json", payload: html, expected: "application/json"},
+		{name: "extension", filename: "dir/style.css", payload: html, expected: "text/css; charset=utf-8"},
+		{name: "unknown extension", filename: "file.unknown-ext", payload: html, expected: "text/html; charset=utf-8"},
+		{name: "sniffed", payload: html, expected: "text/html; charset=utf-8"},
+		{name: "invalid declared", declared: "invalid/", payload: "%PDF-1.7", expected: "application/pdf"},
+		{name: "binary", payload: "\x00\x01\x02", expected: "application/octet-stream"},
+		{name: "empty", payload: "", expected: "text/plain; charset=utf-8"},
+		{name: "large", payload: html + strings.Repeat("a", 2*sizeToDetectType), expected: "text/html; charset=utf-8"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			contentType, r, err := detectContentType(tc.declared, tc.filename, strings.NewReader(tc.payload))
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, contentType)
+
+			payload, err := io.ReadAll(r)
+			require.NoError(t, err)
+			require.Equal(t, tc.payload, string(payload))
+		})
+	}
+}
+
+func TestIsGenericContentType(t *testing.T) {
+	for contentType, expected := range map[string]bool{
+		"":                                  true,
+		"application/octet-stream":          true,
+		"Application/Octet-Stream":          true,
+		"application/x-www-form-urlencoded": true,
+		"text/plain; charset=utf-8":         false,
+		"image/png":                         false,
+	} {
+		require.Equal(t, expected, isGenericContentType(contentType), contentType)
+	}
+}
+
+func TestWithContentType(t *testing.T) {
+	u := &Uploader{settings: &Settings{}}
+
+	newAttribute := func(key, val string) object.Attribute {
+		attr := object.NewAttribute()
+		attr.SetKey(key)
+		attr.SetValue(val)
+		return *attr
+	}
+
+	payload := []byte("<html></html>")
+	fileName := newAttribute(object.AttributeFileName, "index.html")
+
+	t.Run("disabled", func(t *testing.T) {
+		attrs, r, err := u.withContentType([]object.Attribute{fileName}, "", bytes.NewReader(payload))
+		require.NoError(t, err)
+		require.Len(t, attrs, 1)
+		data, err := io.ReadAll(r)
+		require.NoError(t, err)
+		require.Equal(t, payload, data)
+	})
+
+	u.settings.SetDetectContentType(true)
+
+	t.Run("detected", func(t *testing.T) {
+		attrs, _, err := u.withContentType([]object.Attribute{fileName}, "", bytes.NewReader(payload))
+		require.NoError(t, err)
+		require.Len(t, attrs, 2)
+		require.Equal(t, object.AttributeContentType, attrs[1].Key())
+		require.Equal(t, "text/html; charset=utf-8", attrs[1].Value())
+	})
+
+	t.Run("set by header", func(t *testing.T) {
+		contentType := newAttribute(object.AttributeContentType, "text/plain")
+		attrs, _, err := u.withContentType([]object.Attribute{fileName, contentType}, "image/png", bytes.NewReader(payload))
+		require.NoError(t, err)
+		require.Equal(t, []object.Attribute{fileName, contentType}, attrs)
+	})
+}
@@ -0,0 +1,256 @@
+package uploader
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/nspcc-dev/neofs-http-gw/response"
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+)
+
+// Formats of the archives extracted on upload, see extractQueryParam.
+const (
+	extractQueryParam = "extract"
+
+	extractZip   = "zip"
+	extractTar   = "tar"
+	extractTarGz = "tar.gz"
+)
+
+// errExtractChecksum is returned if the checksum of extracted archive is
+// provided. The entries are stored before the archive is read to the end,
+// so the checksum can't be checked in advance.
+var errExtractChecksum = errors.New("checksum headers aren't supported on archive extraction")
+
+// errArchiveTooLarge is returned if the zip archive saved to disk before
+// extraction exceeds the size limit.
+var errArchiveTooLarge = errors.New("archive exceeds the maximum size")
+
+// archiveEntry is a regular file from the uploaded archive.
+type archiveEntry struct {
+	name     string
+	modified time.Time
+	r        io.Reader
+}
+
+// archiveSpool is the directory and the size limit of zip archives saved to
+// disk before extraction, zero size means no limit.
+type archiveSpool struct {
+	dir     string
+	maxSize uint64
+}
+
+func isExtractFormat(format string) bool {
+	switch format {
+	case extractZip, extractTar, extractTarGz:
+		return true
+	default:
+		return false
+	}
+}
+
+// extractArchive stores every regular file from the archive as a separate
+// object. Failures of entries are reported in their results, the failure
+// of reading the archive is reported as the last result with the archive name.
+func (u *Uploader) extractArchive(c *fasthttp.RequestCtx, idCnr cid.ID, filtered map[string]string, format, name string, r io.Reader, details bool) []partPutResponse {
+	var (
+		results []partPutResponse
+		log     = u.log.With(zap.String("cid", idCnr.EncodeToString()), zap.String("archive", name))
+	)
+
+	spool := archiveSpool{dir: u.settings.ExtractDir(), maxSize: u.settings.ExtractMaxSize()}
+
+	err := walkArchive(format, r, spool, func(entry archiveEntry) {
+		res := partPutResponse{FileName: entry.name}
+
+		var ok bool
+		if entry.name, ok = archiveEntryPath(entry.name); !ok {
+			log.Error("invalid archive entry name", zap.String("entry", res.FileName))
+			res.Error = "invalid archive entry name"
+			results = append(results, res)
+			return
+		}
+		res.FileName = entry.name
+
+		attributes, reader, err := u.withContentType(u.entryAttributes(filtered, entry), "", entry.r)
+		payload := newChecksumReader(reader, expectedChecksums{})
+		var idObj oid.ID
+		if err == nil {
+			idObj, err = u.putObject(c, idCnr, attributes, payload)
+		}
+		if err != nil {
+			log.Error("could not store file in neofs", zap.String("entry", entry.name), zap.Error(err))
+			res.Error = "could not store file in neofs: " + err.Error()
+		} else {
+			res.id = idObj
+			res.ObjectID = idObj.EncodeToString()
+			res.Checksum = payload.checksum()
+			if details {
+				res.putDetails = newPutDetails(payload.size(), attributes)
+			}
+		}
+
+		results = append(results, res)
+	})
+	if err != nil {
+		log.Error("could not read archive", zap.Error(err))
+		results = append(results, partPutResponse{FileName: name, Error: "could not read archive: " + err.Error()})
+	}
+
+	return results
+}
+
+// uploadExtracted stores entries of the archive from the request body and
+// responds with the results for every entry.
+func (u *Uploader) uploadExtracted(c *fasthttp.RequestCtx, idCnr cid.ID, filtered map[string]string, format, name string, body io.Reader) {
+	details := acceptsPutDetails(c)
+	results := u.extractArchive(c, idCnr, filtered, format, name, body, details)
+
+	if err := newMultiPutResponse(idCnr, results).encode(c); err != nil {
+		u.log.Error("could not encode response", zap.Error(err))
+		response.Error(c, "could not encode response", fasthttp.StatusBadRequest)
+		return
+	}
+
+	c.Response.SetStatusCode(multiPutStatus(results))
+	c.Response.Header.SetContentType(putResponseContentType(details))
+}
+
+// entryAttributes prepares attributes of the archive entry. FilePath, FileName
+// and Timestamp attributes are set from the entry, other ones are the same
+// for all the entries.
+func (u *Uploader) entryAttributes(filtered map[string]string, entry archiveEntry) []object.Attribute {
+	attributes := make(map[string]string, len(filtered)+3)
+	for key, val := range filtered {
+		attributes[key] = val
+	}
+
+	attributes[object.AttributeFilePath] = entry.name
+	attributes[object.AttributeFileName] = path.Base(entry.name)
+	if !entry.modified.IsZero() {
+		attributes[object.AttributeTimestamp] = strconv.FormatInt(entry.modified.Unix(), 10)
+	}
+
+	return u.objectAttributes(attributes, "")
+}
+
+// archiveEntryPath returns the cleaned relative path of the entry. Paths
+// can't point outside the root, so "../file" is "file".
+func archiveEntryPath(name string) (string, bool) {
+	if !utf8.ValidString(name) {
+		return "", false
+	}
+
+	name = strings.TrimPrefix(path.Clean("/"+strings.ReplaceAll(name, "\\", "/")), "/")
+	return name, name != ""
+}
+
+// walkArchive calls f for every regular file of the archive in the specified
+// format. Entry payload can be read only in f. Zip archives are saved to the
+// spool first.
+func walkArchive(format string, r io.Reader, spool archiveSpool, f func(archiveEntry)) error {
+	switch format {
+	case extractZip:
+		return walkZip(r, spool, f)
+	case extractTar:
+		return walkTar(r, f)
+	case extractTarGz:
+		gr, err := gzip.NewReader(r)
+		if err != nil {
+			return err
+		}
+		if err = walkTar(gr, f); err != nil {
+			return err
+		}
+		return gr.Close()
+	default:
+		return fmt.Errorf("unknown archive format: %s", format)
+	}
+}
+
+func walkTar(r io.Reader, f func(archiveEntry)) error {
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		f(archiveEntry{name: hdr.Name, modified: hdr.ModTime, r: tr})
+	}
+}
+
+// walkZip saves the archive to the temporary file, because zip can't be read
+// sequentially without the central directory at the end.
+func walkZip(r io.Reader, spool archiveSpool, f func(archiveEntry)) error {
+	tmp, err := os.CreateTemp(spool.dir, "neofs-http-gw-extract-*.zip")
+	if err != nil {
+		return fmt.Errorf("could not create temporary file: %w", err)
+	}
+	defer func() {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+	}()
+
+	if spool.maxSize > 0 && spool.maxSize < math.MaxInt64 {
+		// one extra byte is read to find out the limit is exceeded
+		r = io.LimitReader(r, int64(spool.maxSize)+1)
+	}
+
+	size, err := io.Copy(tmp, r)
+	if err != nil {
+		return fmt.Errorf("could not receive archive: %w", err)
+	}
+	if spool.maxSize > 0 && uint64(size) > spool.maxSize {
+		return errArchiveTooLarge
+	}
+
+	zr, err := zip.NewReader(tmp, size)
+	if err != nil {
+		return err
+	}
+
+	for _, file := range zr.File {
+		if !file.Mode().IsRegular() {
+			continue
+		}
+
+		if err = walkZipFile(file, f); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func walkZipFile(file *zip.File, f func(archiveEntry)) error {
+	rc, err := file.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	f(archiveEntry{name: file.Name, modified: file.Modified, r: rc})
+	return nil
+}
@@ -0,0 +1,182 @@
+package uploader
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	"github.com/stretchr/testify/require"
+)
+
+func TestArchiveEntryPath(t *testing.T) {
+	for name, expected := range map[string]string{
+		"file.txt":           "file.txt",
+		"dir/file.txt":       "dir/file.txt",
+		"/dir/file.txt":      "dir/file.txt",
+		"./dir//file.txt":    "dir/file.txt",
+		"../../etc/passwd":   "etc/passwd",
+		"dir\\win\\file.txt": "dir/win/file.txt",
+	} {
+		res, ok := archiveEntryPath(name)
+		require.True(t, ok, name)
+		require.Equal(t, expected, res, name)
+	}
+
+	for _, name := range []string{"", "/", "..", "\xff.txt"} {
+		_, ok := archiveEntryPath(name)
+		require.False(t, ok, name)
+	}
+}
+
+func TestWalkArchive(t *testing.T) {
+	var (
+		modified = time.Date(2022, 11, 15, 10, 30, 0, 0, time.UTC)
+		files    = map[string]string{
+			"index.html":     "<html></html>",
+			"css/style.css":  "body {}",
+			"img/empty.png":  "",
+			"docs/readme.md": "# Readme",
+		}
+		order = []string{"index.html", "css/style.css", "img/empty.png", "docs/readme.md"}
+	)
+
+	zipArchive := func() []byte {
+		var buf bytes.Buffer
+		zw := zip.NewWriter(&buf)
+		_, err := zw.CreateHeader(&zip.FileHeader{Name: "css/"})
+		require.NoError(t, err)
+		for _, name := range order {
+			w, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: modified})
+			require.NoError(t, err)
+			_, err = w.Write([]byte(files[name]))
+			require.NoError(t, err)
+		}
+		require.NoError(t, zw.Close())
+		return buf.Bytes()
+	}
+
+	tarArchive := func(w io.Writer) {
+		tw := tar.NewWriter(w)
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: "css/", Typeflag: tar.TypeDir, Mode: 0755}))
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: "link", Linkname: "index.html", Typeflag: tar.TypeSymlink}))
+		for _, name := range order {
+			require.NoError(t, tw.WriteHeader(&tar.Header{
+				Name:     name,
+				Typeflag: tar.TypeReg,
+				Mode:     0644,
+				Size:     int64(len(files[name])),
+				ModTime:  modified,
+			}))
+			_, err := tw.Write([]byte(files[name]))
+			require.NoError(t, err)
+		}
+		require.NoError(t, tw.Close())
+	}
+
+	var tarBuf, tarGzBuf bytes.Buffer
+	tarArchive(&tarBuf)
+	gw := gzip.NewWriter(&tarGzBuf)
+	tarArchive(gw)
+	require.NoError(t, gw.Close())
+
+	for format, data := range map[string][]byte{
+		extractZip:   zipArchive(),
+		extractTar:   tarBuf.Bytes(),
+		extractTarGz: tarGzBuf.Bytes(),
+	} {
+		t.Run(format, func(t *testing.T) {
+			var names []string
+			err := walkArchive(format, bytes.NewReader(data), archiveSpool{dir: t.TempDir()}, func(entry archiveEntry) {
+				payload, err := io.ReadAll(entry.r)
+				require.NoError(t, err)
+				require.Equal(t, files[entry.name], string(payload))
+				require.True(t, modified.Equal(entry.modified))
+				names = append(names, entry.name)
+			})
+			require.NoError(t, err)
+			require.Equal(t, order, names)
+		})
+	}
+
+	t.Run("invalid archive", func(t *testing.T) {
+		for _, format := range []string{extractZip, extractTar, extractTarGz} {
+			err := walkArchive(format, bytes.NewReader([]byte("not an archive, but long enough to be read as a header")), archiveSpool{}, func(archiveEntry) {})
+			require.Error(t, err, format)
+		}
+	})
+
+	t.Run("empty archive", func(t *testing.T) {
+		var zipBuf, tarBuf bytes.Buffer
+		require.NoError(t, zip.NewWriter(&zipBuf).Close())
+		require.NoError(t, tar.NewWriter(&tarBuf).Close())
+
+		for format, data := range map[string][]byte{extractZip: zipBuf.Bytes(), extractTar: tarBuf.Bytes()} {
+			err := walkArchive(format, bytes.NewReader(data), archiveSpool{}, func(archiveEntry) {
+				t.Fatal("no entries expected")
+			})
+			require.NoError(t, err, format)
+		}
+	})
+
+	t.Run("zip size limit", func(t *testing.T) {
+		data := zipArchive()
+		dir := t.TempDir()
+
+		err := walkArchive(extractZip, bytes.NewReader(data), archiveSpool{dir: dir, maxSize: uint64(len(data) - 1)}, func(archiveEntry) {
+			t.Fatal("no entries expected")
+		})
+		require.ErrorIs(t, err, errArchiveTooLarge)
+
+		var entries int
+		err = walkArchive(extractZip, bytes.NewReader(data), archiveSpool{dir: dir, maxSize: uint64(len(data))}, func(archiveEntry) {
+			entries++
+		})
+		require.NoError(t, err)
+		require.Equal(t, len(order), entries)
+
+		// temporary files are removed
+		files, err := os.ReadDir(dir)
+		require.NoError(t, err)
+		require.Empty(t, files)
+	})
+}
+
+func TestEntryAttributes(t *testing.T) {
+	u := &Uploader{settings: &Settings{}}
+	u.settings.SetDefaultTimestamp(true)
+
+	attributesMap := func(attrs []object.Attribute) map[string]string {
+		res := make(map[string]string, len(attrs))
+		for _, attr := range attrs {
+			res[attr.Key()] = attr.Value()
+		}
+		return res
+	}
+
+	filtered := map[string]string{
+		"MyAttribute":            "value",
+		object.AttributeFileName: "archive.zip",
+	}
+
+	attrs := attributesMap(u.entryAttributes(filtered, archiveEntry{
+		name:     "dir/file.txt",
+		modified: time.Unix(1668509580, 0),
+	}))
+	require.Equal(t, map[string]string{
+		"MyAttribute":             "value",
+		object.AttributeFileName:  "file.txt",
+		object.AttributeFilePath:  "dir/file.txt",
+		object.AttributeTimestamp: "1668509580",
+	}, attrs)
+	require.Equal(t, "archive.zip", filtered[object.AttributeFileName])
+
+	attrs = attributesMap(u.entryAttributes(filtered, archiveEntry{name: "file.txt"}))
+	require.Equal(t, "file.txt", attrs[object.AttributeFilePath])
+	require.Contains(t, attrs, object.AttributeTimestamp)
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"fmt"
 	"math"
+	"mime"
 	"strconv"
 	"time"
 
@@ -15,6 +16,10 @@ import (
 
 var neofsAttributeHeaderPrefixes = [...][]byte{[]byte("Neofs-"), []byte("NEOFS-"), []byte("neofs-")}
 
+// headerValueDecoder decodes encoded-words in attribute values. Only UTF-8,
+// US-ASCII and ISO-8859-1 charsets are supported.
+var headerValueDecoder = new(mime.WordDecoder)
+
 func systemTranslator(key, prefix []byte) []byte {
 	// replace the specified prefix with `__NEOFS__`
 	key = bytes.Replace(key, prefix, []byte(utils.SystemAttributePrefix), 1)
@@ -65,8 +70,17 @@ func filterHeaders(l *zap.Logger, header *fasthttp.RequestHeader) (map[string]st
 			return
 		}
 
-		// make string representation of key / val
+		// make string representation of key / val,
+		// the value can be encoded according to RFC 2047
 		k, v := string(clearKey), string(val)
+		if bytes.Contains(val, []byte("=?")) {
+			decoded, decErr := headerValueDecoder.DecodeHeader(v)
+			if decErr != nil {
+				err = fmt.Errorf("couldn't decode value of header %s: %w", string(key), decErr)
+				return
+			}
+			v = decoded
+		}
 
 		result[k] = v
 
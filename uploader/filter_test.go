@@ -34,6 +34,27 @@ func TestFilter(t *testing.T) {
 		require.Error(t, err)
 	})
 
+	t.Run("encoded values", func(t *testing.T) {
+		req := &fasthttp.RequestHeader{}
+		req.DisableNormalizing()
+		req.Add("X-Attribute-FileName", "=?UTF-8?b?0YTQsNC50LsudHh0?=")
+		req.Add("X-Attribute-Quoted", "=?UTF-8?B?PT9VVEYtOD9CP2RHVnpkQT09Pz0=?=")
+		result, err := filterHeaders(log, req)
+		require.NoError(t, err)
+		require.Equal(t, map[string]string{
+			"FileName": "файл.txt",
+			"Quoted":   "=?UTF-8?B?dGVzdA==?=",
+		}, result)
+	})
+
+	t.Run("unknown charset error", func(t *testing.T) {
+		req := &fasthttp.RequestHeader{}
+		req.DisableNormalizing()
+		req.Add("X-Attribute-FileName", "=?KOI8-R?B?xsHKzC50eHQ=?=")
+		_, err := filterHeaders(log, req)
+		require.Error(t, err)
+	})
+
 	req := &fasthttp.RequestHeader{}
 	req.DisableNormalizing()
 
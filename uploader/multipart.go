@@ -14,11 +14,9 @@ type MultipartFile interface {
 	FileName() string
 }
 
-func fetchMultipartFile(l *zap.Logger, r io.Reader, boundary string) (MultipartFile, error) {
-	// To have a custom buffer (3mb) the custom multipart reader is used.
-	// https://github.com/nspcc-dev/neofs-http-gw/issues/148
-	reader := multipart.NewReader(r, boundary)
-
+// nextMultipartFile returns the next part of the multipart form containing
+// a file. When there are no more files, the error io.EOF is returned.
+func nextMultipartFile(l *zap.Logger, reader *multipart.Reader) (MultipartFile, error) {
 	for {
 		part, err := reader.NextPart()
 		if err != nil {
@@ -43,3 +41,21 @@ func fetchMultipartFile(l *zap.Logger, r io.Reader, boundary string) (MultipartF
 		return part, nil
 	}
 }
+
+// multipartContentType returns Content-Type header of the file part.
+func multipartContentType(file MultipartFile) string {
+	if part, ok := file.(*multipart.Part); ok {
+		return part.Header.Get("Content-Type")
+	}
+	return ""
+}
+
+// multipartChecksums returns checksums of the file from the headers of its
+// part.
+func multipartChecksums(file MultipartFile) (expectedChecksums, error) {
+	part, ok := file.(*multipart.Part)
+	if !ok {
+		return expectedChecksums{}, nil
+	}
+	return parseChecksumHeaders(part.Header.Get)
+}
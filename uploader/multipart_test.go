@@ -8,6 +8,7 @@ import (
 	"os"
 	"testing"
 
+	custom "github.com/nspcc-dev/neofs-http-gw/uploader/multipart"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
 )
@@ -89,7 +90,7 @@ func customMultipart(filename string) error {
 		return err
 	}
 
-	file, err := fetchMultipartFile(logger, r, bound)
+	file, err := nextMultipartFile(logger, custom.NewReader(r, bound))
 	if err != nil {
 		return err
 	}
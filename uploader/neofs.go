@@ -0,0 +1,67 @@
+package uploader
+
+import (
+	"context"
+	"io"
+
+	"github.com/nspcc-dev/neofs-sdk-go/bearer"
+	"github.com/nspcc-dev/neofs-sdk-go/container"
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	"github.com/nspcc-dev/neofs-sdk-go/netmap"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	"github.com/nspcc-dev/neofs-sdk-go/pool"
+)
+
+// neoFS represents virtual connection to the NeoFS network used by Uploader.
+type neoFS interface {
+	// PutObject saves the object with the payload read to the end and
+	// returns its ID. Bearer token is optional.
+	PutObject(ctx context.Context, hdr object.Object, payload io.Reader, btoken *bearer.Token) (oid.ID, error)
+
+	// DeleteObject removes the object. Bearer token is optional.
+	DeleteObject(ctx context.Context, addr oid.Address, btoken *bearer.Token) error
+
+	// Container reads the container by its ID.
+	Container(ctx context.Context, id cid.ID) (container.Container, error)
+
+	// NetworkInfo returns the current NeoFS network information.
+	NetworkInfo(ctx context.Context) (netmap.NetworkInfo, error)
+}
+
+// poolNeoFS implements neoFS using the connection pool.
+type poolNeoFS struct {
+	pool *pool.Pool
+}
+
+func (x poolNeoFS) PutObject(ctx context.Context, hdr object.Object, payload io.Reader, btoken *bearer.Token) (oid.ID, error) {
+	var prm pool.PrmObjectPut
+	prm.SetHeader(hdr)
+	prm.SetPayload(payload)
+	if btoken != nil {
+		prm.UseBearer(*btoken)
+	}
+
+	return x.pool.PutObject(ctx, prm)
+}
+
+func (x poolNeoFS) DeleteObject(ctx context.Context, addr oid.Address, btoken *bearer.Token) error {
+	var prm pool.PrmObjectDelete
+	prm.SetAddress(addr)
+	if btoken != nil {
+		prm.UseBearer(*btoken)
+	}
+
+	return x.pool.DeleteObject(ctx, prm)
+}
+
+func (x poolNeoFS) Container(ctx context.Context, id cid.ID) (container.Container, error) {
+	var prm pool.PrmContainerGet
+	prm.SetContainerID(id)
+
+	return x.pool.GetContainer(ctx, prm)
+}
+
+func (x poolNeoFS) NetworkInfo(ctx context.Context) (netmap.NetworkInfo, error) {
+	return x.pool.NetworkInfo(ctx)
+}
@@ -0,0 +1,449 @@
+package uploader
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nspcc-dev/neofs-http-gw/response"
+	"github.com/nspcc-dev/neofs-http-gw/tokens"
+	"github.com/nspcc-dev/neofs-http-gw/utils"
+	"github.com/nspcc-dev/neofs-sdk-go/bearer"
+	"github.com/nspcc-dev/neofs-sdk-go/client"
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+)
+
+// Resumable uploads implement the core protocol of tus 1.0 with creation,
+// termination and expiration extensions, see https://tus.io/protocols/resumable-upload.html.
+const (
+	tusVersion    = "1.0.0"
+	tusExtensions = "creation,termination,expiration"
+
+	hdrTusResumable  = "Tus-Resumable"
+	hdrTusVersion    = "Tus-Version"
+	hdrTusExtension  = "Tus-Extension"
+	hdrTusMaxSize    = "Tus-Max-Size"
+	hdrUploadLength  = "Upload-Length"
+	hdrUploadOffset  = "Upload-Offset"
+	hdrUploadMeta    = "Upload-Metadata"
+	hdrUploadExpires = "Upload-Expires"
+	hdrObjectID      = "X-Object-Id"
+	hdrContainerID   = "X-Container-Id"
+
+	resumableContentType     = "application/offset+octet-stream"
+	resumableCleanupInterval = 10 * time.Minute
+)
+
+// ResumableOptions handles requests to discover the resumable upload
+// protocol parameters.
+func (u *Uploader) ResumableOptions(c *fasthttp.RequestCtx) {
+	if !u.resumableEnabled(c) {
+		return
+	}
+
+	c.Response.Header.Set(hdrTusResumable, tusVersion)
+	c.Response.Header.Set(hdrTusVersion, tusVersion)
+	c.Response.Header.Set(hdrTusExtension, tusExtensions)
+	if maxSize := u.settings.ResumableMaxSize(); maxSize > 0 {
+		c.Response.Header.Set(hdrTusMaxSize, strconv.FormatUint(maxSize, 10))
+	}
+	c.Response.SetStatusCode(fasthttp.StatusNoContent)
+}
+
+// ResumableCreate handles requests to create the resumable upload. Object
+// attributes are set by the headers of this request the same way as for
+// Upload.
+func (u *Uploader) ResumableCreate(c *fasthttp.RequestCtx) {
+	var (
+		scid, _ = c.UserValue("cid").(string)
+		log     = u.log.With(zap.String("cid", scid))
+		now     = time.Now()
+	)
+
+	if !u.resumableEnabled(c) || !checkTusResumable(c) {
+		return
+	}
+
+	idCnr, err := utils.GetContainerID(u.appCtx, scid, u.containerResolver)
+	if err != nil {
+		log.Error("wrong container id", zap.Error(err))
+		response.Error(c, "wrong container id", fasthttp.StatusBadRequest)
+		return
+	}
+
+	length, err := strconv.ParseUint(string(c.Request.Header.Peek(hdrUploadLength)), 10, 64)
+	if err != nil {
+		log.Error("invalid upload length", zap.Error(err))
+		response.Error(c, "invalid "+hdrUploadLength+" header", fasthttp.StatusBadRequest)
+		return
+	}
+	if maxSize := u.settings.ResumableMaxSize(); maxSize > 0 && length > maxSize {
+		response.Error(c, "upload length exceeds maximum size "+strconv.FormatUint(maxSize, 10), fasthttp.StatusRequestEntityTooLarge)
+		return
+	}
+
+	if err = tokens.StoreBearerToken(c); err != nil {
+		log.Error("could not fetch bearer token", zap.Error(err))
+		response.Error(c, "could not fetch bearer token", fasthttp.StatusBadRequest)
+		return
+	}
+	btoken, _ := tokens.LoadBearerToken(c)
+	if btoken != nil && (!btoken.VerifySignature() || !btoken.AssertContainer(*idCnr)) {
+		log.Error("invalid bearer token")
+		response.Error(c, "invalid bearer token", fasthttp.StatusBadRequest)
+		return
+	}
+
+	// check the container exists to not stage the payload in vain
+	if _, err = u.neofs.Container(u.appCtx, *idCnr); err != nil {
+		log.Error("could not check container existence", zap.Error(err))
+		if client.IsErrContainerNotFound(err) {
+			response.Error(c, "container not found", fasthttp.StatusNotFound)
+		} else {
+			response.Error(c, "could not get container: "+err.Error(), fasthttp.StatusBadRequest)
+		}
+		return
+	}
+
+	meta, err := parseUploadMetadata(string(c.Request.Header.Peek(hdrUploadMeta)))
+	if err != nil {
+		log.Error("invalid upload metadata", zap.Error(err))
+		response.Error(c, "invalid "+hdrUploadMeta+" header: "+err.Error(), fasthttp.StatusBadRequest)
+		return
+	}
+
+	filtered, err := u.filterRequestHeaders(c)
+	if err != nil {
+		log.Error("could not process headers", zap.Error(err))
+		response.Error(c, err.Error(), fasthttp.StatusBadRequest)
+		return
+	}
+	// content type from metadata is set by tus clients
+	if _, ok := filtered[object.AttributeContentType]; !ok && meta["filetype"] != "" {
+		filtered[object.AttributeContentType] = meta["filetype"]
+	}
+
+	id, err := newResumableID()
+	if err != nil {
+		log.Error("could not generate upload id", zap.Error(err))
+		response.Error(c, "could not generate upload id", fasthttp.StatusInternalServerError)
+		return
+	}
+
+	info := &resumableInfo{
+		ID:          id,
+		ContainerID: idCnr.EncodeToString(),
+		Length:      length,
+		FileName:    meta["filename"],
+		Attributes:  filtered,
+		Expires:     now.Add(u.settings.ResumableLifetime()),
+	}
+	if btoken != nil {
+		// the object is stored with the token of the creator whatever
+		// the token of the last request is
+		info.BearerToken = btoken.Marshal()
+	}
+
+	dir := u.settings.ResumableDir()
+	if err = u.resumable.create(dir, info, u.settings.ResumableMaxTotalSize(), now); err != nil {
+		log.Error("could not create upload", zap.Error(err))
+		if errors.Is(err, errResumableNoSpace) {
+			response.Error(c, "could not create upload: "+err.Error(), fasthttp.StatusInsufficientStorage)
+		} else {
+			response.Error(c, "could not create upload", fasthttp.StatusInternalServerError)
+		}
+		return
+	}
+
+	if u.resumable.needCleanup(now, resumableCleanupInterval) {
+		go u.cleanupResumable(dir)
+	}
+
+	log.Debug("resumable upload created", zap.String("upload", id), zap.Uint64("length", length))
+
+	c.Response.Header.Set(hdrTusResumable, tusVersion)
+	c.Response.Header.Set(fasthttp.HeaderLocation, "/resumable/"+info.ContainerID+"/"+id)
+	c.Response.Header.Set(hdrUploadExpires, info.Expires.UTC().Format(http.TimeFormat))
+	c.Response.SetStatusCode(fasthttp.StatusCreated)
+}
+
+// ResumableOffset handles requests to get the offset the upload should be
+// resumed from. The ID of the stored object is returned in X-Object-Id
+// header when the upload is finished.
+func (u *Uploader) ResumableOffset(c *fasthttp.RequestCtx) {
+	if !u.resumableEnabled(c) || !checkTusResumable(c) {
+		return
+	}
+
+	info, offset, ok := u.loadResumable(c)
+	if !ok {
+		return
+	}
+
+	setResumableHeaders(c, info, offset)
+	c.Response.Header.Set(fasthttp.HeaderCacheControl, "no-store")
+	c.Response.SetStatusCode(fasthttp.StatusOK)
+}
+
+// ResumablePatch handles requests to append the payload to the upload.
+// The object is stored in NeoFS when the whole payload is received.
+func (u *Uploader) ResumablePatch(c *fasthttp.RequestCtx) {
+	var (
+		upload, _ = c.UserValue("id").(string)
+		log       = u.log.With(zap.String("upload", upload))
+		dir       = u.settings.ResumableDir()
+	)
+
+	if !u.resumableEnabled(c) || !checkTusResumable(c) {
+		return
+	}
+
+	if string(c.Request.Header.ContentType()) != resumableContentType {
+		response.Error(c, "content type must be "+resumableContentType, fasthttp.StatusUnsupportedMediaType)
+		return
+	}
+
+	reqOffset, err := strconv.ParseUint(string(c.Request.Header.Peek(hdrUploadOffset)), 10, 64)
+	if err != nil {
+		response.Error(c, "invalid "+hdrUploadOffset+" header", fasthttp.StatusBadRequest)
+		return
+	}
+
+	if !u.resumable.lock(upload) {
+		response.Error(c, "upload is being written", fasthttp.StatusConflict)
+		return
+	}
+	defer u.resumable.unlock(upload)
+
+	info, offset, ok := u.loadResumable(c)
+	if !ok {
+		return
+	}
+	if info.ObjectID != "" {
+		response.Error(c, "upload is finished", fasthttp.StatusConflict)
+		return
+	}
+	if reqOffset != offset {
+		c.Response.Header.Set(hdrUploadOffset, strconv.FormatUint(offset, 10))
+		response.Error(c, "upload offset mismatch, expected "+strconv.FormatUint(offset, 10), fasthttp.StatusConflict)
+		return
+	}
+
+	// reject the payload of known size before it's received
+	if size := c.Request.Header.ContentLength(); size > 0 && uint64(size) > info.Length-offset {
+		response.Error(c, "could not write payload: "+errResumableTooLarge.Error(), fasthttp.StatusRequestEntityTooLarge)
+		return
+	}
+
+	var body io.Reader = c.RequestBodyStream()
+	if body == nil {
+		// request body isn't streamed, so it has been already read
+		body = bytes.NewReader(c.Request.Body())
+	}
+
+	offset, err = u.resumable.append(dir, info, offset, body)
+	if err != nil {
+		log.Error("could not write payload", zap.Uint64("offset", offset), zap.Error(err))
+		status := fasthttp.StatusBadRequest
+		if errors.Is(err, errResumableTooLarge) {
+			status = fasthttp.StatusRequestEntityTooLarge
+		}
+		response.Error(c, "could not write payload: "+err.Error(), status)
+		return
+	}
+
+	if offset == info.Length {
+		if err = u.finishResumable(dir, info); err != nil {
+			log.Error("could not store file in neofs", zap.Error(err))
+			response.Error(c, "could not store file in neofs: "+err.Error(), fasthttp.StatusBadRequest)
+			return
+		}
+		log.Debug("resumable upload finished", zap.String("oid", info.ObjectID))
+	}
+
+	setResumableHeaders(c, info, offset)
+	c.Response.SetStatusCode(fasthttp.StatusNoContent)
+}
+
+// ResumableDelete handles requests to terminate the upload.
+func (u *Uploader) ResumableDelete(c *fasthttp.RequestCtx) {
+	upload, _ := c.UserValue("id").(string)
+
+	if !u.resumableEnabled(c) || !checkTusResumable(c) {
+		return
+	}
+
+	if !u.resumable.lock(upload) {
+		response.Error(c, "upload is being written", fasthttp.StatusConflict)
+		return
+	}
+	defer u.resumable.unlock(upload)
+
+	if _, _, ok := u.loadResumable(c); !ok {
+		return
+	}
+
+	if err := u.resumable.remove(u.settings.ResumableDir(), upload); err != nil {
+		u.log.Error("could not remove upload", zap.String("upload", upload), zap.Error(err))
+		response.Error(c, "could not remove upload", fasthttp.StatusInternalServerError)
+		return
+	}
+
+	c.Response.Header.Set(hdrTusResumable, tusVersion)
+	c.Response.SetStatusCode(fasthttp.StatusNoContent)
+}
+
+// resumableEnabled responds with 404 if resumable uploads are disabled.
+func (u *Uploader) resumableEnabled(c *fasthttp.RequestCtx) bool {
+	if !u.settings.ResumableEnabled() {
+		response.Error(c, "resumable uploads are disabled", fasthttp.StatusNotFound)
+		return false
+	}
+	return true
+}
+
+// checkTusResumable checks the protocol version requested by the client.
+func checkTusResumable(c *fasthttp.RequestCtx) bool {
+	if string(c.Request.Header.Peek(hdrTusResumable)) != tusVersion {
+		c.Response.Header.Set(hdrTusVersion, tusVersion)
+		response.Error(c, "unsupported protocol version", fasthttp.StatusPreconditionFailed)
+		return false
+	}
+	return true
+}
+
+// loadResumable returns the upload requested by the route parameters.
+// Error response is written if the upload can't be loaded.
+func (u *Uploader) loadResumable(c *fasthttp.RequestCtx) (*resumableInfo, uint64, bool) {
+	var (
+		scid, _   = c.UserValue("cid").(string)
+		upload, _ = c.UserValue("id").(string)
+		log       = u.log.With(zap.String("cid", scid), zap.String("upload", upload))
+	)
+
+	idCnr, err := utils.GetContainerID(u.appCtx, scid, u.containerResolver)
+	if err != nil {
+		log.Error("wrong container id", zap.Error(err))
+		response.Error(c, "wrong container id", fasthttp.StatusBadRequest)
+		return nil, 0, false
+	}
+
+	info, offset, err := u.resumable.load(u.settings.ResumableDir(), upload, time.Now())
+	if err == nil && info.ContainerID != idCnr.EncodeToString() {
+		err = errResumableNotFound
+	}
+	if err != nil {
+		log.Error("could not load upload", zap.Error(err))
+		if errors.Is(err, errResumableNotFound) {
+			response.Error(c, "upload not found", fasthttp.StatusNotFound)
+		} else {
+			response.Error(c, "could not load upload", fasthttp.StatusInternalServerError)
+		}
+		return nil, 0, false
+	}
+
+	return info, offset, true
+}
+
+// finishResumable stores the staged payload as an object with the bearer
+// token the upload was created with.
+func (u *Uploader) finishResumable(dir string, info *resumableInfo) error {
+	var idCnr cid.ID
+	if err := idCnr.DecodeString(info.ContainerID); err != nil {
+		return fmt.Errorf("invalid container id: %w", err)
+	}
+
+	var btoken *bearer.Token
+	if len(info.BearerToken) != 0 {
+		btoken = new(bearer.Token)
+		if err := btoken.Unmarshal(info.BearerToken); err != nil {
+			return fmt.Errorf("invalid bearer token: %w", err)
+		}
+	}
+
+	payload, err := u.resumable.payload(dir, info)
+	if err != nil {
+		return err
+	}
+	defer payload.Close()
+
+	attributes, reader, err := u.withContentType(u.objectAttributes(info.Attributes, info.FileName), "", payload)
+	if err != nil {
+		return err
+	}
+
+	owner, btoken := u.ownerAndBearerToken(btoken)
+	idObj, err := u.putObjectAs(owner, btoken, idCnr, attributes, reader)
+	if err != nil {
+		return err
+	}
+
+	info.ObjectID = idObj.EncodeToString()
+	if err = u.resumable.finish(dir, info); err != nil {
+		// the object is stored anyway
+		u.log.Warn("could not finish upload", zap.String("upload", info.ID), zap.Error(err))
+	}
+
+	return nil
+}
+
+func (u *Uploader) cleanupResumable(dir string) {
+	removed, err := u.resumable.cleanup(dir, time.Now())
+	if err != nil {
+		u.log.Warn("could not remove expired uploads", zap.Int("removed", removed), zap.Error(err))
+		return
+	}
+	u.log.Debug("expired uploads removed", zap.Int("removed", removed))
+}
+
+func setResumableHeaders(c *fasthttp.RequestCtx, info *resumableInfo, offset uint64) {
+	c.Response.Header.Set(hdrTusResumable, tusVersion)
+	c.Response.Header.Set(hdrUploadOffset, strconv.FormatUint(offset, 10))
+	c.Response.Header.Set(hdrUploadLength, strconv.FormatUint(info.Length, 10))
+	c.Response.Header.Set(hdrUploadExpires, info.Expires.UTC().Format(http.TimeFormat))
+	if info.ObjectID != "" {
+		c.Response.Header.Set(hdrObjectID, info.ObjectID)
+		c.Response.Header.Set(hdrContainerID, info.ContainerID)
+	}
+}
+
+// parseUploadMetadata parses Upload-Metadata header value: comma-separated
+// pairs of the key and base64 encoded value separated by space.
+func parseUploadMetadata(header string) (map[string]string, error) {
+	res := make(map[string]string)
+	if strings.TrimSpace(header) == "" {
+		return res, nil
+	}
+
+	for _, pair := range strings.Split(header, ",") {
+		fields := strings.Fields(pair)
+		if len(fields) == 0 || len(fields) > 2 {
+			return nil, fmt.Errorf("invalid pair: '%s'", pair)
+		}
+
+		key := fields[0]
+		if _, ok := res[key]; ok {
+			return nil, fmt.Errorf("duplicated key: %s", key)
+		}
+
+		var val []byte
+		if len(fields) == 2 {
+			var err error
+			if val, err = base64.StdEncoding.DecodeString(fields[1]); err != nil {
+				return nil, fmt.Errorf("invalid value of key %s: %w", key, err)
+			}
+		}
+		res[key] = string(val)
+	}
+
+	return res, nil
+}
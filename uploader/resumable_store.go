@@ -0,0 +1,322 @@
+package uploader
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	resumableInfoExt    = ".info"
+	resumablePayloadExt = ".bin"
+	resumableIDSize     = 16
+)
+
+var (
+	errResumableNotFound = errors.New("upload not found")
+	errResumableTooLarge = errors.New("payload exceeds upload length")
+	errResumableNoSpace  = errors.New("total size of uploads exceeds the limit")
+)
+
+// resumableInfo describes the resumable upload staged on disk.
+type resumableInfo struct {
+	ID          string            `json:"id"`
+	ContainerID string            `json:"container_id"`
+	Length      uint64            `json:"length"`
+	FileName    string            `json:"filename,omitempty"`
+	Attributes  map[string]string `json:"attributes,omitempty"`
+	Expires     time.Time         `json:"expires"`
+	// BearerToken is the binary token the upload was created with.
+	BearerToken []byte `json:"bearer_token,omitempty"`
+	// ObjectID is set when the upload is finished, the payload is removed then.
+	ObjectID string `json:"object_id,omitempty"`
+}
+
+// resumableStore keeps resumable uploads in the directory. Every upload is
+// a pair of files: <id>.info with JSON encoded resumableInfo and <id>.bin
+// with the payload received so far, so the offset is the size of the latter.
+// Uploads are locked while they're written to.
+type resumableStore struct {
+	mu          sync.Mutex
+	locked      map[string]struct{}
+	lastCleanup time.Time
+
+	// createMu serializes upload creation, so the total size of uploads
+	// can't exceed the limit because of concurrent requests.
+	createMu sync.Mutex
+}
+
+func newResumableStore() *resumableStore {
+	return &resumableStore{locked: make(map[string]struct{})}
+}
+
+// lock marks the upload as being written to. Returns false if it's already
+// locked.
+func (s *resumableStore) lock(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.locked[id]; ok {
+		return false
+	}
+	s.locked[id] = struct{}{}
+	return true
+}
+
+func (s *resumableStore) unlock(id string) {
+	s.mu.Lock()
+	delete(s.locked, id)
+	s.mu.Unlock()
+}
+
+// needCleanup checks if expired uploads haven't been removed for the interval.
+func (s *resumableStore) needCleanup(now time.Time, interval time.Duration) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if now.Sub(s.lastCleanup) < interval {
+		return false
+	}
+	s.lastCleanup = now
+	return true
+}
+
+func newResumableID() (string, error) {
+	id := make([]byte, resumableIDSize)
+	if _, err := rand.Read(id); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(id), nil
+}
+
+// isValidResumableID checks the format of the upload ID, so it can't be used
+// to access files outside the directory.
+func isValidResumableID(id string) bool {
+	if len(id) != 2*resumableIDSize {
+		return false
+	}
+	_, err := hex.DecodeString(id)
+	return err == nil
+}
+
+// create stores the upload info and an empty payload. Lengths of unfinished
+// uploads which aren't expired are reserved, so the total size of them
+// is checked to not exceed maxTotal (zero means no limit).
+func (s *resumableStore) create(dir string, info *resumableInfo, maxTotal uint64, now time.Time) error {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("could not create directory: %w", err)
+	}
+
+	s.createMu.Lock()
+	defer s.createMu.Unlock()
+
+	if maxTotal > 0 {
+		total, err := s.reserved(dir, now)
+		if err != nil {
+			return err
+		}
+		if total+info.Length > maxTotal || total+info.Length < total {
+			return errResumableNoSpace
+		}
+	}
+
+	f, err := os.OpenFile(filepath.Join(dir, info.ID+resumablePayloadExt), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("could not create payload file: %w", err)
+	}
+	if err = f.Close(); err != nil {
+		return fmt.Errorf("could not create payload file: %w", err)
+	}
+
+	return s.save(dir, info)
+}
+
+// reserved returns the total length of unfinished uploads which aren't
+// expired.
+func (s *resumableStore) reserved(dir string, now time.Time) (uint64, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return 0, fmt.Errorf("could not read directory: %w", err)
+	}
+
+	var total uint64
+	for _, entry := range entries {
+		id := strings.TrimSuffix(entry.Name(), resumableInfoExt)
+		if id == entry.Name() || !isValidResumableID(id) {
+			continue
+		}
+
+		info, _, err := s.load(dir, id, now)
+		if err != nil {
+			// expired or removed concurrently
+			continue
+		}
+		if info.ObjectID == "" {
+			total += info.Length
+		}
+	}
+
+	return total, nil
+}
+
+// save writes the upload info to the temporary file and renames it, so
+// the info can't be read partially written.
+func (s *resumableStore) save(dir string, info *resumableInfo) error {
+	data, err := json.Marshal(info)
+	if err != nil {
+		return fmt.Errorf("could not encode upload info: %w", err)
+	}
+
+	name := filepath.Join(dir, info.ID+resumableInfoExt)
+	if err = os.WriteFile(name+".tmp", data, 0600); err != nil {
+		return fmt.Errorf("could not write upload info: %w", err)
+	}
+	if err = os.Rename(name+".tmp", name); err != nil {
+		return fmt.Errorf("could not write upload info: %w", err)
+	}
+
+	return nil
+}
+
+// load returns the upload info and the offset. Expired uploads aren't found.
+func (s *resumableStore) load(dir, id string, now time.Time) (*resumableInfo, uint64, error) {
+	if !isValidResumableID(id) {
+		return nil, 0, errResumableNotFound
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, id+resumableInfoExt))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, 0, errResumableNotFound
+		}
+		return nil, 0, fmt.Errorf("could not read upload info: %w", err)
+	}
+
+	info := new(resumableInfo)
+	if err = json.Unmarshal(data, info); err != nil {
+		return nil, 0, fmt.Errorf("could not decode upload info: %w", err)
+	}
+	if !now.Before(info.Expires) {
+		return nil, 0, errResumableNotFound
+	}
+	if info.ObjectID != "" {
+		return info, info.Length, nil
+	}
+
+	stat, err := os.Stat(filepath.Join(dir, id+resumablePayloadExt))
+	if err != nil {
+		return nil, 0, fmt.Errorf("could not stat payload file: %w", err)
+	}
+
+	return info, uint64(stat.Size()), nil
+}
+
+// append writes data from the reader to the end of the payload. Data is
+// written until the reader returns an error, so the upload can be resumed
+// from the returned offset whatever happened. If the reader has more data
+// than the upload length, the written data is discarded and
+// errResumableTooLarge is returned with the initial offset.
+func (s *resumableStore) append(dir string, info *resumableInfo, offset uint64, r io.Reader) (uint64, error) {
+	f, err := os.OpenFile(filepath.Join(dir, info.ID+resumablePayloadExt), os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return offset, fmt.Errorf("could not open payload file: %w", err)
+	}
+
+	start, left := offset, info.Length-offset
+	n, err := io.Copy(f, io.LimitReader(r, int64(left)))
+	offset += uint64(n)
+
+	if err == nil && uint64(n) == left {
+		// check there is nothing after the expected payload
+		var b [1]byte
+		if m, _ := io.ReadFull(r, b[:]); m > 0 {
+			err = errResumableTooLarge
+			if truncErr := f.Truncate(int64(start)); truncErr != nil {
+				err = fmt.Errorf("%w, could not discard payload: %v", errResumableTooLarge, truncErr)
+			} else {
+				offset = start
+			}
+		}
+	}
+
+	if closeErr := f.Close(); err == nil && closeErr != nil {
+		err = fmt.Errorf("could not write payload file: %w", closeErr)
+	}
+
+	return offset, err
+}
+
+// payload opens the payload for reading.
+func (s *resumableStore) payload(dir string, info *resumableInfo) (*os.File, error) {
+	return os.Open(filepath.Join(dir, info.ID+resumablePayloadExt))
+}
+
+// finish saves the ID of the stored object and removes the payload. The info
+// is kept until it's expired to report the object ID.
+func (s *resumableStore) finish(dir string, info *resumableInfo) error {
+	if err := s.save(dir, info); err != nil {
+		return err
+	}
+	if err := os.Remove(filepath.Join(dir, info.ID+resumablePayloadExt)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("could not remove payload file: %w", err)
+	}
+	return nil
+}
+
+// remove deletes the upload files.
+func (s *resumableStore) remove(dir, id string) error {
+	if !isValidResumableID(id) {
+		return errResumableNotFound
+	}
+
+	var res error
+	for _, ext := range [...]string{resumablePayloadExt, resumableInfoExt} {
+		if err := os.Remove(filepath.Join(dir, id+ext)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			res = err
+		}
+	}
+	return res
+}
+
+// cleanup removes expired uploads which aren't locked. Returns the number
+// of removed uploads.
+func (s *resumableStore) cleanup(dir string, now time.Time) (int, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return 0, nil
+		}
+		return 0, err
+	}
+
+	var removed int
+	for _, entry := range entries {
+		id := strings.TrimSuffix(entry.Name(), resumableInfoExt)
+		if id == entry.Name() || !isValidResumableID(id) {
+			continue
+		}
+
+		if _, _, err = s.load(dir, id, now); !errors.Is(err, errResumableNotFound) {
+			continue
+		}
+		if !s.lock(id) {
+			continue
+		}
+		err = s.remove(dir, id)
+		s.unlock(id)
+		if err != nil {
+			return removed, err
+		}
+		removed++
+	}
+
+	return removed, nil
+}
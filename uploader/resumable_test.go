@@ -0,0 +1,310 @@
+package uploader
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	"github.com/nspcc-dev/neo-go/pkg/crypto/keys"
+	"github.com/nspcc-dev/neofs-sdk-go/bearer"
+	cidtest "github.com/nspcc-dev/neofs-sdk-go/container/id/test"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+)
+
+func TestParseUploadMetadata(t *testing.T) {
+	t.Run("empty", func(t *testing.T) {
+		meta, err := parseUploadMetadata("")
+		require.NoError(t, err)
+		require.Empty(t, meta)
+	})
+
+	t.Run("valid", func(t *testing.T) {
+		meta, err := parseUploadMetadata("filename d29ybGRfZG9taW5hdGlvbl9wbGFuLnBkZg==, is_confidential,filetype dGV4dC9wbGFpbg==")
+		require.NoError(t, err)
+		require.Equal(t, map[string]string{
+			"filename":        "world_domination_plan.pdf",
+			"is_confidential": "",
+			"filetype":        "text/plain",
+		}, meta)
+	})
+
+	for _, header := range []string{
+		"filename not-base64",
+		"filename dGVzdA== extra",
+		"filename dGVzdA==,,filetype dGV4dC9wbGFpbg==",
+		"filename dGVzdA==,filename dGVzdA==",
+	} {
+		_, err := parseUploadMetadata(header)
+		require.Error(t, err, header)
+	}
+}
+
+func TestResumableStore(t *testing.T) {
+	var (
+		dir   = t.TempDir()
+		now   = time.Now()
+		store = newResumableStore()
+	)
+
+	newUpload := func(t *testing.T, length uint64, expires time.Time) *resumableInfo {
+		id, err := newResumableID()
+		require.NoError(t, err)
+
+		info := &resumableInfo{
+			ID:          id,
+			ContainerID: "container",
+			Length:      length,
+			FileName:    "file.txt",
+			Attributes:  map[string]string{"MyAttribute": "value"},
+			Expires:     expires,
+		}
+		require.NoError(t, store.create(dir, info, 0, now))
+		return info
+	}
+
+	t.Run("append and finish", func(t *testing.T) {
+		info := newUpload(t, 10, now.Add(time.Hour))
+
+		loaded, offset, err := store.load(dir, info.ID, now)
+		require.NoError(t, err)
+		require.Zero(t, offset)
+		require.Equal(t, info.Attributes, loaded.Attributes)
+
+		offset, err = store.append(dir, info, 0, strings.NewReader("hello"))
+		require.NoError(t, err)
+		require.EqualValues(t, 5, offset)
+
+		// interrupted request keeps the received data
+		_, err = store.append(dir, info, 5, io.MultiReader(strings.NewReader("wo"), iotest.ErrReader(io.ErrUnexpectedEOF)))
+		require.Error(t, err)
+
+		_, offset, err = store.load(dir, info.ID, now)
+		require.NoError(t, err)
+		require.EqualValues(t, 7, offset)
+
+		offset, err = store.append(dir, info, offset, strings.NewReader("rld"))
+		require.NoError(t, err)
+		require.EqualValues(t, 10, offset)
+
+		payload, err := store.payload(dir, info)
+		require.NoError(t, err)
+		data, err := io.ReadAll(payload)
+		require.NoError(t, err)
+		require.NoError(t, payload.Close())
+		require.Equal(t, "helloworld", string(data))
+
+		info.ObjectID = "object"
+		require.NoError(t, store.finish(dir, info))
+		require.NoFileExists(t, filepath.Join(dir, info.ID+resumablePayloadExt))
+
+		loaded, offset, err = store.load(dir, info.ID, now)
+		require.NoError(t, err)
+		require.EqualValues(t, 10, offset)
+		require.Equal(t, "object", loaded.ObjectID)
+	})
+
+	t.Run("too large payload", func(t *testing.T) {
+		info := newUpload(t, 3, now.Add(time.Hour))
+
+		offset, err := store.append(dir, info, 0, bytes.NewReader([]byte("four")))
+		require.ErrorIs(t, err, errResumableTooLarge)
+		require.Zero(t, offset)
+
+		// the written data is discarded
+		_, offset, err = store.load(dir, info.ID, now)
+		require.NoError(t, err)
+		require.Zero(t, offset)
+
+		offset, err = store.append(dir, info, 0, bytes.NewReader([]byte("one")))
+		require.NoError(t, err)
+		require.EqualValues(t, 3, offset)
+	})
+
+	t.Run("total size limit", func(t *testing.T) {
+		dir := t.TempDir()
+		store := newResumableStore()
+
+		newInfo := func(length uint64, expires time.Time) *resumableInfo {
+			id, err := newResumableID()
+			require.NoError(t, err)
+			return &resumableInfo{ID: id, Length: length, Expires: expires}
+		}
+
+		require.NoError(t, store.create(dir, newInfo(6, now.Add(time.Hour)), 10, now))
+		require.NoError(t, store.create(dir, newInfo(4, now.Add(-time.Minute)), 10, now)) // expired
+		require.ErrorIs(t, store.create(dir, newInfo(5, now.Add(time.Hour)), 10, now), errResumableNoSpace)
+
+		finished := newInfo(4, now.Add(time.Hour))
+		require.NoError(t, store.create(dir, finished, 10, now))
+		finished.ObjectID = "object"
+		require.NoError(t, store.finish(dir, finished))
+
+		// finished uploads don't reserve space
+		require.NoError(t, store.create(dir, newInfo(4, now.Add(time.Hour)), 10, now))
+		require.ErrorIs(t, store.create(dir, newInfo(1, now.Add(time.Hour)), 10, now), errResumableNoSpace)
+
+		// no limit
+		require.NoError(t, store.create(dir, newInfo(100, now.Add(time.Hour)), 0, now))
+	})
+
+	t.Run("invalid id", func(t *testing.T) {
+		_, _, err := store.load(dir, "../"+strings.Repeat("0", 2*resumableIDSize-3), now)
+		require.ErrorIs(t, err, errResumableNotFound)
+		require.ErrorIs(t, store.remove(dir, "../file"), errResumableNotFound)
+	})
+
+	t.Run("lock", func(t *testing.T) {
+		require.True(t, store.lock("id"))
+		require.False(t, store.lock("id"))
+		store.unlock("id")
+		require.True(t, store.lock("id"))
+		store.unlock("id")
+	})
+
+	t.Run("cleanup", func(t *testing.T) {
+		dir := t.TempDir()
+		store := newResumableStore()
+
+		expired := &resumableInfo{ID: strings.Repeat("1", 2*resumableIDSize), Expires: now.Add(-time.Minute)}
+		active := &resumableInfo{ID: strings.Repeat("2", 2*resumableIDSize), Expires: now.Add(time.Minute)}
+		require.NoError(t, store.create(dir, expired, 0, now))
+		require.NoError(t, store.create(dir, active, 0, now))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "other.info"), nil, 0600))
+
+		_, _, err := store.load(dir, expired.ID, now)
+		require.ErrorIs(t, err, errResumableNotFound)
+
+		removed, err := store.cleanup(dir, now)
+		require.NoError(t, err)
+		require.Equal(t, 1, removed)
+		require.NoFileExists(t, filepath.Join(dir, expired.ID+resumableInfoExt))
+		require.NoFileExists(t, filepath.Join(dir, expired.ID+resumablePayloadExt))
+		require.FileExists(t, filepath.Join(dir, active.ID+resumableInfoExt))
+		require.FileExists(t, filepath.Join(dir, "other.info"))
+
+		require.True(t, store.needCleanup(now, time.Minute))
+		require.False(t, store.needCleanup(now.Add(time.Second), time.Minute))
+	})
+}
+
+func TestResumableHandlers(t *testing.T) {
+	u, neofs := newTestUploader(t)
+	u.settings.SetResumableEnabled(true)
+	u.settings.SetResumableDir(t.TempDir())
+	u.settings.SetResumableMaxSize(10)
+	u.settings.SetResumableMaxTotalSize(15)
+	u.settings.SetResumableLifetime(time.Hour)
+
+	idCnr := cidtest.ID()
+
+	createWithToken := func(cnr string, length int, btoken *bearer.Token) *fasthttp.RequestCtx {
+		c := new(fasthttp.RequestCtx)
+		c.Request.Header.SetMethod(fasthttp.MethodPost)
+		c.Request.Header.Set(hdrTusResumable, tusVersion)
+		c.Request.Header.Set(hdrUploadLength, strconv.Itoa(length))
+		if btoken != nil {
+			c.Request.Header.Set(fasthttp.HeaderAuthorization, "Bearer "+base64.StdEncoding.EncodeToString(btoken.Marshal()))
+		}
+		c.SetUserValue("cid", cnr)
+		u.ResumableCreate(c)
+		return c
+	}
+
+	create := func(cnr string, length int) *fasthttp.RequestCtx {
+		return createWithToken(cnr, length, nil)
+	}
+
+	patch := func(location string, offset int, body string) *fasthttp.RequestCtx {
+		parts := strings.Split(location, "/")
+		c := new(fasthttp.RequestCtx)
+		c.Request.Header.SetMethod(fasthttp.MethodPatch)
+		c.Request.Header.Set(hdrTusResumable, tusVersion)
+		c.Request.Header.SetContentType(resumableContentType)
+		c.Request.Header.Set(hdrUploadOffset, strconv.Itoa(offset))
+		c.Request.SetBodyString(body)
+		c.SetUserValue("cid", parts[2])
+		c.SetUserValue("id", parts[3])
+		u.ResumablePatch(c)
+		return c
+	}
+
+	t.Run("missing container", func(t *testing.T) {
+		missing := cidtest.ID()
+		neofs.missing[missing] = struct{}{}
+
+		c := create(missing.EncodeToString(), 5)
+		require.Equal(t, fasthttp.StatusNotFound, c.Response.StatusCode())
+	})
+
+	t.Run("too large", func(t *testing.T) {
+		c := create(idCnr.EncodeToString(), 11)
+		require.Equal(t, fasthttp.StatusRequestEntityTooLarge, c.Response.StatusCode())
+	})
+
+	var location string
+	t.Run("create", func(t *testing.T) {
+		c := create(idCnr.EncodeToString(), 10)
+		require.Equal(t, fasthttp.StatusCreated, c.Response.StatusCode(), string(c.Response.Body()))
+		location = string(c.Response.Header.Peek(fasthttp.HeaderLocation))
+	})
+
+	t.Run("total size limit", func(t *testing.T) {
+		c := create(idCnr.EncodeToString(), 6)
+		require.Equal(t, fasthttp.StatusInsufficientStorage, c.Response.StatusCode())
+	})
+
+	t.Run("payload exceeds length", func(t *testing.T) {
+		c := patch(location, 0, "more than ten bytes")
+		require.Equal(t, fasthttp.StatusRequestEntityTooLarge, c.Response.StatusCode())
+
+		c = patch(location, 0, "hello")
+		require.Equal(t, fasthttp.StatusNoContent, c.Response.StatusCode(), string(c.Response.Body()))
+		require.Equal(t, "5", string(c.Response.Header.Peek(hdrUploadOffset)))
+	})
+
+	t.Run("finish", func(t *testing.T) {
+		stored := len(neofs.objects)
+		c := patch(location, 5, "world")
+		require.Equal(t, fasthttp.StatusNoContent, c.Response.StatusCode(), string(c.Response.Body()))
+		require.NotEmpty(t, c.Response.Header.Peek(hdrObjectID))
+		require.Len(t, neofs.objects, stored+1)
+
+		// finished upload doesn't reserve space anymore
+		c = create(idCnr.EncodeToString(), 10)
+		require.Equal(t, fasthttp.StatusCreated, c.Response.StatusCode(), string(c.Response.Body()))
+	})
+
+	t.Run("bearer token", func(t *testing.T) {
+		key, err := keys.NewPrivateKey()
+		require.NoError(t, err)
+
+		var btoken bearer.Token
+		btoken.SetExp(100)
+		c := createWithToken(idCnr.EncodeToString(), 4, &btoken)
+		require.Equal(t, fasthttp.StatusBadRequest, c.Response.StatusCode(), "unsigned token")
+
+		require.NoError(t, btoken.Sign(key.PrivateKey))
+		c = createWithToken(idCnr.EncodeToString(), 4, &btoken)
+		require.Equal(t, fasthttp.StatusCreated, c.Response.StatusCode(), string(c.Response.Body()))
+
+		// the token of the creator is used, the last request has none
+		c = patch(string(c.Response.Header.Peek(fasthttp.HeaderLocation)), 0, "data")
+		require.Equal(t, fasthttp.StatusNoContent, c.Response.StatusCode(), string(c.Response.Body()))
+
+		var idObj oid.ID
+		require.NoError(t, idObj.DecodeString(string(c.Response.Header.Peek(hdrObjectID))))
+		require.Contains(t, neofs.objects, idObj)
+		stored := neofs.objects[idObj].btoken
+		require.NotNil(t, stored)
+		require.Equal(t, btoken.Marshal(), stored.Marshal())
+	})
+}
@@ -1,22 +1,27 @@
 package uploader
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"path"
 	"strconv"
+	"strings"
 	"time"
 
+	v2object "github.com/nspcc-dev/neofs-api-go/v2/object"
 	"github.com/nspcc-dev/neofs-http-gw/resolver"
 	"github.com/nspcc-dev/neofs-http-gw/response"
 	"github.com/nspcc-dev/neofs-http-gw/tokens"
+	"github.com/nspcc-dev/neofs-http-gw/uploader/multipart"
 	"github.com/nspcc-dev/neofs-http-gw/utils"
 	"github.com/nspcc-dev/neofs-sdk-go/bearer"
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
 	"github.com/nspcc-dev/neofs-sdk-go/object"
 	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
-	"github.com/nspcc-dev/neofs-sdk-go/pool"
 	"github.com/nspcc-dev/neofs-sdk-go/user"
 	"github.com/valyala/fasthttp"
 	"go.uber.org/atomic"
@@ -24,18 +29,24 @@ import (
 )
 
 const (
-	jsonHeader   = "application/json; charset=UTF-8"
-	drainBufSize = 4096
+	jsonHeader         = "application/json; charset=UTF-8"
+	drainBufSize       = 4096
+	filenameQueryParam = "filename"
+
+	// putDetailsMediaType is accepted by clients which need the details
+	// of the stored object in the upload response.
+	putDetailsMediaType = "application/vnd.neofs.upload.v2+json"
 )
 
 // Uploader is an upload request handler.
 type Uploader struct {
 	appCtx            context.Context
 	log               *zap.Logger
-	pool              *pool.Pool
+	neofs             neoFS
 	ownerID           *user.ID
 	settings          *Settings
 	containerResolver *resolver.ContainerResolver
+	resumable         *resumableStore
 }
 
 type epochDurations struct {
@@ -46,7 +57,15 @@ type epochDurations struct {
 
 // Settings stores reloading parameters, so it has to provide atomic getters and setters.
 type Settings struct {
-	defaultTimestamp atomic.Bool
+	defaultTimestamp  atomic.Bool
+	detectContentType atomic.Bool
+	resumableEnabled  atomic.Bool
+	resumableDir      atomic.String
+	resumableMaxSize  atomic.Uint64
+	resumableMaxTotal atomic.Uint64
+	resumableLifetime atomic.Duration
+	extractDir        atomic.String
+	extractMaxSize    atomic.Uint64
 }
 
 func (s *Settings) DefaultTimestamp() bool {
@@ -57,31 +76,115 @@ func (s *Settings) SetDefaultTimestamp(val bool) {
 	s.defaultTimestamp.Store(val)
 }
 
+// DetectContentType returns true if ContentType attribute is set on upload.
+func (s *Settings) DetectContentType() bool {
+	return s.detectContentType.Load()
+}
+
+func (s *Settings) SetDetectContentType(val bool) {
+	s.detectContentType.Store(val)
+}
+
+// ResumableEnabled returns true if resumable uploads are enabled.
+func (s *Settings) ResumableEnabled() bool {
+	return s.resumableEnabled.Load()
+}
+
+func (s *Settings) SetResumableEnabled(val bool) {
+	s.resumableEnabled.Store(val)
+}
+
+// ResumableDir returns the directory to stage resumable uploads in.
+func (s *Settings) ResumableDir() string {
+	return s.resumableDir.Load()
+}
+
+func (s *Settings) SetResumableDir(val string) {
+	s.resumableDir.Store(val)
+}
+
+// ResumableMaxSize returns the maximum size of resumable upload, zero means
+// no limit.
+func (s *Settings) ResumableMaxSize() uint64 {
+	return s.resumableMaxSize.Load()
+}
+
+func (s *Settings) SetResumableMaxSize(val uint64) {
+	s.resumableMaxSize.Store(val)
+}
+
+// ResumableMaxTotalSize returns the maximum total size of unfinished
+// resumable uploads staged on disk, zero means no limit.
+func (s *Settings) ResumableMaxTotalSize() uint64 {
+	return s.resumableMaxTotal.Load()
+}
+
+func (s *Settings) SetResumableMaxTotalSize(val uint64) {
+	s.resumableMaxTotal.Store(val)
+}
+
+// ResumableLifetime returns the time resumable upload is kept after it's
+// created.
+func (s *Settings) ResumableLifetime() time.Duration {
+	return s.resumableLifetime.Load()
+}
+
+func (s *Settings) SetResumableLifetime(val time.Duration) {
+	s.resumableLifetime.Store(val)
+}
+
+// ExtractDir returns the directory to save zip archives in before extraction.
+func (s *Settings) ExtractDir() string {
+	return s.extractDir.Load()
+}
+
+func (s *Settings) SetExtractDir(val string) {
+	s.extractDir.Store(val)
+}
+
+// ExtractMaxSize returns the maximum size of zip archive saved before
+// extraction, zero means no limit.
+func (s *Settings) ExtractMaxSize() uint64 {
+	return s.extractMaxSize.Load()
+}
+
+func (s *Settings) SetExtractMaxSize(val uint64) {
+	s.extractMaxSize.Store(val)
+}
+
 // New creates a new Uploader using specified logger, connection pool and
 // other options.
 func New(ctx context.Context, params *utils.AppParams, settings *Settings) *Uploader {
 	return &Uploader{
 		appCtx:            ctx,
 		log:               params.Logger,
-		pool:              params.Pool,
+		neofs:             poolNeoFS{pool: params.Pool},
 		ownerID:           params.Owner,
 		settings:          settings,
 		containerResolver: params.Resolver,
+		resumable:         newResumableStore(),
 	}
 }
 
-// Upload handles multipart upload request.
+// Upload handles multipart upload request. Every file part of the form is
+// stored as a separate object.
 func (u *Uploader) Upload(c *fasthttp.RequestCtx) {
 	var (
 		file       MultipartFile
-		idObj      oid.ID
-		addr       oid.Address
+		results    []partPutResponse
 		scid, _    = c.UserValue("cid").(string)
 		log        = u.log.With(zap.String("cid", scid))
 		bodyStream = c.RequestBodyStream()
 		drainBuf   = make([]byte, drainBufSize)
+		details    = acceptsPutDetails(c)
+		extract    = string(c.QueryArgs().Peek(extractQueryParam))
 	)
 
+	if extract != "" && !isExtractFormat(extract) {
+		response.Error(c, "unknown archive format: "+extract, fasthttp.StatusBadRequest)
+		return
+	}
+
 	if err := tokens.StoreBearerToken(c); err != nil {
 		log.Error("could not fetch bearer token", zap.Error(err))
 		response.Error(c, "could not fetch bearer token", fasthttp.StatusBadRequest)
@@ -95,55 +198,249 @@ func (u *Uploader) Upload(c *fasthttp.RequestCtx) {
 		return
 	}
 
-	defer func() {
-		// If the temporary reader can be closed - let's close it.
-		if file == nil {
-			return
+	filtered, err := u.filterRequestHeaders(c)
+	if err != nil {
+		log.Error("could not process headers", zap.Error(err))
+		response.Error(c, err.Error(), fasthttp.StatusBadRequest)
+		return
+	}
+
+	// To have a custom buffer (3mb) the custom multipart reader is used.
+	// https://github.com/nspcc-dev/neofs-http-gw/issues/148
+	reader := multipart.NewReader(bodyStream, string(c.Request.Header.MultipartFormBoundary()))
+	if file, err = nextMultipartFile(log, reader); err != nil {
+		log.Error("could not receive multipart/form", zap.Error(err))
+		response.Error(c, "could not receive multipart/form: "+err.Error(), fasthttp.StatusBadRequest)
+		return
+	}
+
+	for {
+		res := partPutResponse{FileName: file.FileName()}
+
+		if expected, err := multipartChecksums(file); err != nil {
+			log.Error("could not process checksum headers", zap.String("filename", res.FileName), zap.Error(err))
+			res.Error = err.Error()
+		} else if extract != "" {
+			if !expected.empty() {
+				res.Error = errExtractChecksum.Error()
+			} else {
+				results = append(results, u.extractArchive(c, *idCnr, filtered, extract, res.FileName, file, details)...)
+			}
+		} else {
+			attributes, reader, err := u.withContentType(u.objectAttributes(filtered, res.FileName), multipartContentType(file), file)
+			payload := newChecksumReader(reader, expected)
+			var idObj oid.ID
+			if err == nil {
+				idObj, err = u.putVerifiedObject(c, *idCnr, attributes, payload)
+			}
+			if err != nil {
+				log.Error("could not store file in neofs", zap.String("filename", res.FileName), zap.Error(err))
+				res.Error = "could not store file in neofs: " + err.Error()
+			} else {
+				res.id = idObj
+				res.ObjectID = idObj.EncodeToString()
+				res.Checksum = payload.checksum()
+				if details {
+					res.putDetails = newPutDetails(payload.size(), attributes)
+				}
+			}
 		}
-		err := file.Close()
+
+		// If the temporary reader can be closed - let's close it.
+		err = file.Close()
 		log.Debug(
 			"close temporary multipart/form file",
-			zap.Stringer("address", addr),
-			zap.String("filename", file.FileName()),
+			zap.String("oid", res.ObjectID),
+			zap.String("filename", res.FileName),
 			zap.Error(err),
 		)
-	}()
-	boundary := string(c.Request.Header.MultipartFormBoundary())
-	if file, err = fetchMultipartFile(u.log, bodyStream, boundary); err != nil {
-		log.Error("could not receive multipart/form", zap.Error(err))
-		response.Error(c, "could not receive multipart/form: "+err.Error(), fasthttp.StatusBadRequest)
+
+		// results of extracted archive are already added
+		if extract == "" || res.Error != "" {
+			results = append(results, res)
+		}
+
+		if file, err = nextMultipartFile(log, reader); err != nil {
+			if err != io.EOF {
+				log.Error("could not receive multipart/form", zap.Error(err))
+				results = append(results, partPutResponse{Error: "could not receive multipart/form: " + err.Error()})
+			}
+			break
+		}
+	}
+
+	// When dealing with chunked encoding the last zero-length chunk might
+	// be left unread (because multipart reader only cares about its boundary
+	// and doesn't look further) and it will be (erroneously) interpreted as
+	// the start of the next pipelined header. Thus we need to drain the body
+	// buffer.
+	for {
+		_, err = bodyStream.Read(drainBuf)
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			break
+		}
+	}
+
+	status := fasthttp.StatusOK
+	if len(results) == 1 && extract == "" {
+		// Keep the response of a single file upload as simple as it is.
+		if results[0].Error != "" {
+			response.Error(c, results[0].Error, fasthttp.StatusBadRequest)
+			return
+		}
+
+		var addr oid.Address
+		addr.SetContainer(*idCnr)
+		addr.SetObject(results[0].id)
+		c.Response.Header.Set(fasthttp.HeaderLocation, objectLocation(addr))
+		err = newPutResponse(addr, results[0].Checksum, results[0].putDetails).encode(c)
+	} else {
+		status = multiPutStatus(results)
+		err = newMultiPutResponse(*idCnr, results).encode(c)
+	}
+
+	// Try to return the response, otherwise, if something went wrong, throw an error.
+	if err != nil {
+		log.Error("could not encode response", zap.Error(err))
+		response.Error(c, "could not encode response", fasthttp.StatusBadRequest)
+
 		return
 	}
-	filtered, err := filterHeaders(u.log, &c.Request.Header)
+
+	// Report status code and content type.
+	c.Response.SetStatusCode(status)
+	c.Response.Header.SetContentType(putResponseContentType(details))
+}
+
+// UploadRaw handles upload request with the object payload passed as
+// a request body. File name can be specified by the filename query parameter.
+func (u *Uploader) UploadRaw(c *fasthttp.RequestCtx) {
+	var (
+		addr     oid.Address
+		scid, _  = c.UserValue("cid").(string)
+		filename = string(c.QueryArgs().Peek(filenameQueryParam))
+		extract  = string(c.QueryArgs().Peek(extractQueryParam))
+		log      = u.log.With(zap.String("cid", scid), zap.String("filename", filename))
+	)
+
+	if extract != "" && !isExtractFormat(extract) {
+		response.Error(c, "unknown archive format: "+extract, fasthttp.StatusBadRequest)
+		return
+	}
+
+	if err := tokens.StoreBearerToken(c); err != nil {
+		log.Error("could not fetch bearer token", zap.Error(err))
+		response.Error(c, "could not fetch bearer token", fasthttp.StatusBadRequest)
+		return
+	}
+
+	idCnr, err := utils.GetContainerID(u.appCtx, scid, u.containerResolver)
+	if err != nil {
+		log.Error("wrong container id", zap.Error(err))
+		response.Error(c, "wrong container id", fasthttp.StatusBadRequest)
+		return
+	}
+
+	filtered, err := u.filterRequestHeaders(c)
 	if err != nil {
 		log.Error("could not process headers", zap.Error(err))
 		response.Error(c, err.Error(), fasthttp.StatusBadRequest)
 		return
 	}
+
+	expected, err := parseChecksumHeaders(func(key string) string {
+		return string(c.Request.Header.Peek(key))
+	})
+	if err != nil {
+		log.Error("could not process checksum headers", zap.Error(err))
+		response.Error(c, err.Error(), fasthttp.StatusBadRequest)
+		return
+	}
+
+	var body io.Reader = c.RequestBodyStream()
+	if body == nil {
+		// request body isn't streamed, so it has been already read
+		body = bytes.NewReader(c.Request.Body())
+	}
+
+	if extract != "" {
+		if !expected.empty() {
+			response.Error(c, errExtractChecksum.Error(), fasthttp.StatusBadRequest)
+			return
+		}
+		u.uploadExtracted(c, *idCnr, filtered, extract, filename, body)
+		return
+	}
+
+	attributes, body, err := u.withContentType(u.objectAttributes(filtered, filename), string(c.Request.Header.ContentType()), body)
+	if err != nil {
+		log.Error("could not read payload", zap.Error(err))
+		response.Error(c, "could not read payload: "+err.Error(), fasthttp.StatusBadRequest)
+		return
+	}
+
+	payload := newChecksumReader(body, expected)
+	idObj, err := u.putVerifiedObject(c, *idCnr, attributes, payload)
+	if err != nil {
+		log.Error("could not store file in neofs", zap.Error(err))
+		response.Error(c, "could not store file in neofs: "+err.Error(), fasthttp.StatusBadRequest)
+		return
+	}
+
+	addr.SetObject(idObj)
+	addr.SetContainer(*idCnr)
+
+	var details *putDetails
+	if acceptsPutDetails(c) {
+		details = newPutDetails(payload.size(), attributes)
+	}
+
+	if err = newPutResponse(addr, payload.checksum(), details).encode(c); err != nil {
+		log.Error("could not encode response", zap.Error(err))
+		response.Error(c, "could not encode response", fasthttp.StatusBadRequest)
+		return
+	}
+
+	c.Response.SetStatusCode(fasthttp.StatusOK)
+	c.Response.Header.SetContentType(putResponseContentType(details != nil))
+	c.Response.Header.Set(fasthttp.HeaderLocation, objectLocation(addr))
+}
+
+// filterRequestHeaders returns object attributes set by request headers
+// with expiration headers converted to the expiration epoch.
+func (u *Uploader) filterRequestHeaders(c *fasthttp.RequestCtx) (map[string]string, error) {
+	filtered, err := filterHeaders(u.log, &c.Request.Header)
+	if err != nil {
+		return nil, err
+	}
+
 	if needParseExpiration(filtered) {
-		epochDuration, err := getEpochDurations(c, u.pool)
+		epochDuration, err := getEpochDurations(c, u.neofs)
 		if err != nil {
-			log.Error("could not get epoch durations from network info", zap.Error(err))
-			response.Error(c, "could not get epoch durations from network info: "+err.Error(), fasthttp.StatusBadRequest)
-			return
+			return nil, fmt.Errorf("could not get epoch durations from network info: %w", err)
 		}
 
 		now := time.Now()
 		if rawHeader := c.Request.Header.Peek(fasthttp.HeaderDate); rawHeader != nil {
 			if parsed, err := time.Parse(http.TimeFormat, string(rawHeader)); err != nil {
-				log.Warn("could not parse client time", zap.String("Date header", string(rawHeader)), zap.Error(err))
+				u.log.Warn("could not parse client time", zap.String("Date header", string(rawHeader)), zap.Error(err))
 			} else {
 				now = parsed
 			}
 		}
 
 		if err = prepareExpirationHeader(filtered, epochDuration, now); err != nil {
-			log.Error("could not parse expiration header", zap.Error(err))
-			response.Error(c, "could not parse expiration header: "+err.Error(), fasthttp.StatusBadRequest)
-			return
+			return nil, fmt.Errorf("could not parse expiration header: %w", err)
 		}
 	}
 
+	return filtered, nil
+}
+
+// objectAttributes prepares attributes of the object from filtered headers.
+// FileName and FilePath attributes are set from filename if they weren't
+// set from header, and Timestamp attribute is set if it's enabled by settings.
+func (u *Uploader) objectAttributes(filtered map[string]string, filename string) []object.Attribute {
 	attributes := make([]object.Attribute, 0, len(filtered))
 	// prepares attributes from filtered headers
 	for key, val := range filtered {
@@ -153,11 +450,19 @@ func (u *Uploader) Upload(c *fasthttp.RequestCtx) {
 		attributes = append(attributes, *attribute)
 	}
 	// sets FileName attribute if it wasn't set from header
-	if _, ok := filtered[object.AttributeFileName]; !ok {
-		filename := object.NewAttribute()
-		filename.SetKey(object.AttributeFileName)
-		filename.SetValue(file.FileName())
-		attributes = append(attributes, *filename)
+	if _, ok := filtered[object.AttributeFileName]; !ok && filename != "" {
+		attr := object.NewAttribute()
+		attr.SetKey(object.AttributeFileName)
+		attr.SetValue(path.Base(filename))
+		attributes = append(attributes, *attr)
+	}
+	// sets FilePath attribute if the file name contains directories
+	// and it wasn't set from header
+	if _, ok := filtered[object.AttributeFilePath]; !ok && strings.Contains(filename, "/") {
+		attr := object.NewAttribute()
+		attr.SetKey(object.AttributeFilePath)
+		attr.SetValue(filename)
+		attributes = append(attributes, *attr)
 	}
 	// sets Timestamp attribute if it wasn't set from header and enabled by settings
 	if _, ok := filtered[object.AttributeTimestamp]; !ok && u.settings.DefaultTimestamp() {
@@ -166,56 +471,62 @@ func (u *Uploader) Upload(c *fasthttp.RequestCtx) {
 		timestamp.SetValue(strconv.FormatInt(time.Now().Unix(), 10))
 		attributes = append(attributes, *timestamp)
 	}
+
+	return attributes
+}
+
+// putObject stores the object with specified attributes and payload
+// in the container.
+func (u *Uploader) putObject(c *fasthttp.RequestCtx, idCnr cid.ID, attributes []object.Attribute, payload io.Reader) (oid.ID, error) {
 	id, bt := u.fetchOwnerAndBearerToken(c)
+	return u.putObjectAs(id, bt, idCnr, attributes, payload)
+}
 
+// putObjectAs stores the object owned by the specified user with the bearer
+// token (if it's not nil).
+func (u *Uploader) putObjectAs(owner *user.ID, bt *bearer.Token, idCnr cid.ID, attributes []object.Attribute, payload io.Reader) (oid.ID, error) {
 	obj := object.New()
-	obj.SetContainerID(*idCnr)
-	obj.SetOwnerID(id)
+	obj.SetContainerID(idCnr)
+	obj.SetOwnerID(owner)
 	obj.SetAttributes(attributes...)
 
-	var prm pool.PrmObjectPut
-	prm.SetHeader(*obj)
-	prm.SetPayload(file)
+	return u.neofs.PutObject(u.appCtx, *obj, payload, bt)
+}
 
-	if bt != nil {
-		prm.UseBearer(*bt)
+// putVerifiedObject stores the object and verifies checksums of its payload
+// after that, so the put isn't failed in the middle of the stream. The stored
+// object is removed if checksums don't match.
+func (u *Uploader) putVerifiedObject(c *fasthttp.RequestCtx, idCnr cid.ID, attributes []object.Attribute, payload *checksumReader) (oid.ID, error) {
+	idObj, err := u.putObject(c, idCnr, attributes, payload)
+	if err != nil {
+		return oid.ID{}, err
 	}
 
-	if idObj, err = u.pool.PutObject(u.appCtx, prm); err != nil {
-		log.Error("could not store file in neofs", zap.Error(err))
-		response.Error(c, "could not store file in neofs: "+err.Error(), fasthttp.StatusBadRequest)
-		return
-	}
+	if err = payload.verify(); err != nil {
+		var addr oid.Address
+		addr.SetContainer(idCnr)
+		addr.SetObject(idObj)
 
-	addr.SetObject(idObj)
-	addr.SetContainer(*idCnr)
-
-	// Try to return the response, otherwise, if something went wrong, throw an error.
-	if err = newPutResponse(addr).encode(c); err != nil {
-		log.Error("could not encode response", zap.Error(err))
-		response.Error(c, "could not encode response", fasthttp.StatusBadRequest)
-
-		return
-	}
-	// Multipart is multipart and thus can contain more than one part which
-	// we ignore at the moment. Also, when dealing with chunked encoding
-	// the last zero-length chunk might be left unread (because multipart
-	// reader only cares about its boundary and doesn't look further) and
-	// it will be (erroneously) interpreted as the start of the next
-	// pipelined header. Thus we need to drain the body buffer.
-	for {
-		_, err = bodyStream.Read(drainBuf)
-		if err == io.EOF || err == io.ErrUnexpectedEOF {
-			break
+		_, bt := u.fetchOwnerAndBearerToken(c)
+		if delErr := u.neofs.DeleteObject(u.appCtx, addr, bt); delErr != nil {
+			u.log.Error("could not remove object with checksum mismatch",
+				zap.String("address", addr.EncodeToString()), zap.Error(delErr))
 		}
+		return oid.ID{}, err
 	}
-	// Report status code and content type.
-	c.Response.SetStatusCode(fasthttp.StatusOK)
-	c.Response.Header.SetContentType(jsonHeader)
+
+	return idObj, nil
 }
 
 func (u *Uploader) fetchOwnerAndBearerToken(ctx context.Context) (*user.ID, *bearer.Token) {
-	if tkn, err := tokens.LoadBearerToken(ctx); err == nil && tkn != nil {
+	tkn, _ := tokens.LoadBearerToken(ctx)
+	return u.ownerAndBearerToken(tkn)
+}
+
+// ownerAndBearerToken returns the issuer of the token as the object owner or
+// the gateway owner if there is no token.
+func (u *Uploader) ownerAndBearerToken(tkn *bearer.Token) (*user.ID, *bearer.Token) {
+	if tkn != nil {
 		issuer := bearer.ResolveIssuer(*tkn)
 		return &issuer, tkn
 	}
@@ -223,25 +534,136 @@ func (u *Uploader) fetchOwnerAndBearerToken(ctx context.Context) (*user.ID, *bea
 }
 
 type putResponse struct {
-	ObjectID    string `json:"object_id"`
-	ContainerID string `json:"container_id"`
+	ObjectID    string           `json:"object_id"`
+	ContainerID string           `json:"container_id"`
+	Checksum    *payloadChecksum `json:"checksum,omitempty"`
+	*putDetails
+}
+
+// putDetails describe the stored object. They're returned only if the client
+// accepts putDetailsMediaType, so the response of the older clients isn't
+// changed.
+type putDetails struct {
+	Size            uint64            `json:"size"`
+	Attributes      map[string]string `json:"attributes"`
+	ExpirationEpoch uint64            `json:"expiration_epoch,omitempty"`
 }
 
-func newPutResponse(addr oid.Address) *putResponse {
+func newPutResponse(addr oid.Address, checksum *payloadChecksum, details *putDetails) *putResponse {
 	return &putResponse{
 		ObjectID:    addr.Object().EncodeToString(),
 		ContainerID: addr.Container().EncodeToString(),
+		Checksum:    checksum,
+		putDetails:  details,
+	}
+}
+
+func newPutDetails(size uint64, attributes []object.Attribute) *putDetails {
+	res := &putDetails{
+		Size:       size,
+		Attributes: make(map[string]string, len(attributes)),
 	}
+
+	for _, attr := range attributes {
+		res.Attributes[attr.Key()] = attr.Value()
+		if attr.Key() == v2object.SysAttributeExpEpoch {
+			// the value has been already checked by prepareExpirationHeader
+			res.ExpirationEpoch, _ = strconv.ParseUint(attr.Value(), 10, 64)
+		}
+	}
+
+	return res
+}
+
+// acceptsPutDetails checks if putDetailsMediaType is listed in Accept header.
+func acceptsPutDetails(c *fasthttp.RequestCtx) bool {
+	for _, mediaRange := range strings.Split(string(c.Request.Header.Peek(fasthttp.HeaderAccept)), ",") {
+		if ind := strings.IndexByte(mediaRange, ';'); ind >= 0 {
+			mediaRange = mediaRange[:ind]
+		}
+		if strings.TrimSpace(mediaRange) == putDetailsMediaType {
+			return true
+		}
+	}
+	return false
+}
+
+func putResponseContentType(details bool) string {
+	if details {
+		return putDetailsMediaType + "; charset=UTF-8"
+	}
+	return jsonHeader
+}
+
+// objectLocation returns the path to download the object.
+func objectLocation(addr oid.Address) string {
+	return "/get/" + addr.Container().EncodeToString() + "/" + addr.Object().EncodeToString()
 }
 
 func (pr *putResponse) encode(w io.Writer) error {
+	return encodeJSON(w, pr)
+}
+
+type multiPutResponse struct {
+	ContainerID string            `json:"container_id"`
+	Objects     []partPutResponse `json:"objects"`
+}
+
+type partPutResponse struct {
+	FileName string           `json:"filename"`
+	ObjectID string           `json:"object_id,omitempty"`
+	Checksum *payloadChecksum `json:"checksum,omitempty"`
+	Error    string           `json:"error,omitempty"`
+	*putDetails
+
+	id oid.ID
+}
+
+func newMultiPutResponse(idCnr cid.ID, parts []partPutResponse) *multiPutResponse {
+	if parts == nil {
+		// empty list instead of null, nothing is stored from the empty archive
+		parts = []partPutResponse{}
+	}
+
+	return &multiPutResponse{
+		ContainerID: idCnr.EncodeToString(),
+		Objects:     parts,
+	}
+}
+
+func (pr *multiPutResponse) encode(w io.Writer) error {
+	return encodeJSON(w, pr)
+}
+
+// multiPutStatus returns the status code of the response with several parts:
+// 200 if every part is stored (or there are no parts, e.g. the archive is
+// empty), 207 if some of them failed and 400 if every part failed.
+func multiPutStatus(parts []partPutResponse) int {
+	var failed int
+	for _, part := range parts {
+		if part.Error != "" {
+			failed++
+		}
+	}
+
+	switch {
+	case failed == 0:
+		return fasthttp.StatusOK
+	case failed == len(parts):
+		return fasthttp.StatusBadRequest
+	default:
+		return fasthttp.StatusMultiStatus
+	}
+}
+
+func encodeJSON(w io.Writer, v interface{}) error {
 	enc := json.NewEncoder(w)
 	enc.SetIndent("", "\t")
-	return enc.Encode(pr)
+	return enc.Encode(v)
 }
 
-func getEpochDurations(ctx context.Context, p *pool.Pool) (*epochDurations, error) {
-	networkInfo, err := p.NetworkInfo(ctx)
+func getEpochDurations(ctx context.Context, neofs neoFS) (*epochDurations, error) {
+	networkInfo, err := neofs.NetworkInfo(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,408 @@
+package uploader
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/textproto"
+	"testing"
+
+	v2object "github.com/nspcc-dev/neofs-api-go/v2/object"
+	"github.com/nspcc-dev/neofs-http-gw/resolver"
+	"github.com/nspcc-dev/neofs-sdk-go/bearer"
+	apistatus "github.com/nspcc-dev/neofs-sdk-go/client/status"
+	"github.com/nspcc-dev/neofs-sdk-go/container"
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	cidtest "github.com/nspcc-dev/neofs-sdk-go/container/id/test"
+	"github.com/nspcc-dev/neofs-sdk-go/netmap"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	oidtest "github.com/nspcc-dev/neofs-sdk-go/object/id/test"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+)
+
+type testObject struct {
+	attributes map[string]string
+	payload    []byte
+	btoken     *bearer.Token
+}
+
+// testNeoFS keeps stored objects in memory.
+type testNeoFS struct {
+	objects map[oid.ID]testObject
+	// missing are containers which don't exist, others do.
+	missing map[cid.ID]struct{}
+}
+
+func (x *testNeoFS) PutObject(_ context.Context, hdr object.Object, payload io.Reader, btoken *bearer.Token) (oid.ID, error) {
+	data, err := io.ReadAll(payload)
+	if err != nil {
+		return oid.ID{}, err
+	}
+
+	obj := testObject{attributes: make(map[string]string), payload: data, btoken: btoken}
+	for _, attr := range hdr.Attributes() {
+		obj.attributes[attr.Key()] = attr.Value()
+	}
+
+	id := oidtest.ID()
+	x.objects[id] = obj
+	return id, nil
+}
+
+func (x *testNeoFS) DeleteObject(_ context.Context, addr oid.Address, _ *bearer.Token) error {
+	if _, ok := x.objects[addr.Object()]; !ok {
+		return errors.New("object not found")
+	}
+	delete(x.objects, addr.Object())
+	return nil
+}
+
+func (x *testNeoFS) Container(_ context.Context, id cid.ID) (container.Container, error) {
+	if _, ok := x.missing[id]; ok {
+		return container.Container{}, apistatus.ContainerNotFound{}
+	}
+	return container.Container{}, nil
+}
+
+func (x *testNeoFS) NetworkInfo(context.Context) (netmap.NetworkInfo, error) {
+	return netmap.NetworkInfo{}, errors.New("not implemented")
+}
+
+func newTestUploader(t *testing.T) (*Uploader, *testNeoFS) {
+	cnrResolver, err := resolver.NewContainerResolver(nil, &resolver.Config{})
+	require.NoError(t, err)
+
+	neofs := &testNeoFS{objects: make(map[oid.ID]testObject), missing: make(map[cid.ID]struct{})}
+	return &Uploader{
+		appCtx:            context.Background(),
+		log:               zap.NewNop(),
+		neofs:             neofs,
+		settings:          &Settings{},
+		containerResolver: cnrResolver,
+		resumable:         newResumableStore(),
+	}, neofs
+}
+
+func TestObjectAttributes(t *testing.T) {
+	u := &Uploader{settings: &Settings{}}
+
+	attributesMap := func(attrs []object.Attribute) map[string]string {
+		res := make(map[string]string, len(attrs))
+		for _, attr := range attrs {
+			res[attr.Key()] = attr.Value()
+		}
+		return res
+	}
+
+	t.Run("plain file name", func(t *testing.T) {
+		attrs := u.objectAttributes(map[string]string{"MyAttribute": "value"}, "file.txt")
+		require.Equal(t, map[string]string{
+			"MyAttribute":            "value",
+			object.AttributeFileName: "file.txt",
+		}, attributesMap(attrs))
+	})
+
+	t.Run("file name with directories", func(t *testing.T) {
+		attrs := u.objectAttributes(map[string]string{}, "dir/subdir/file.txt")
+		require.Equal(t, map[string]string{
+			object.AttributeFileName: "file.txt",
+			object.AttributeFilePath: "dir/subdir/file.txt",
+		}, attributesMap(attrs))
+	})
+
+	t.Run("attributes from headers", func(t *testing.T) {
+		attrs := u.objectAttributes(map[string]string{
+			object.AttributeFileName: "name",
+			object.AttributeFilePath: "path/name",
+		}, "dir/file.txt")
+		require.Equal(t, map[string]string{
+			object.AttributeFileName: "name",
+			object.AttributeFilePath: "path/name",
+		}, attributesMap(attrs))
+	})
+
+	t.Run("empty file name", func(t *testing.T) {
+		require.Empty(t, u.objectAttributes(map[string]string{}, ""))
+	})
+
+	t.Run("default timestamp", func(t *testing.T) {
+		u := &Uploader{settings: &Settings{}}
+		u.settings.SetDefaultTimestamp(true)
+
+		attrs := attributesMap(u.objectAttributes(map[string]string{}, "file.txt"))
+		require.Contains(t, attrs, object.AttributeTimestamp)
+	})
+}
+
+func TestPutResponse(t *testing.T) {
+	addr := oidtest.Address()
+
+	expiration := object.NewAttribute()
+	expiration.SetKey(v2object.SysAttributeExpEpoch)
+	expiration.SetValue("100")
+	filename := object.NewAttribute()
+	filename.SetKey(object.AttributeFileName)
+	filename.SetValue("file.txt")
+
+	encode := func(details *putDetails) map[string]interface{} {
+		var buf bytes.Buffer
+		require.NoError(t, newPutResponse(addr, &payloadChecksum{MD5: "md5", SHA256: "sha256"}, details).encode(&buf))
+
+		var res map[string]interface{}
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &res))
+		return res
+	}
+
+	t.Run("without details", func(t *testing.T) {
+		res := encode(nil)
+		require.Equal(t, addr.Object().EncodeToString(), res["object_id"])
+		require.Contains(t, res, "checksum")
+		require.NotContains(t, res, "size")
+		require.NotContains(t, res, "attributes")
+	})
+
+	t.Run("with details", func(t *testing.T) {
+		res := encode(newPutDetails(0, []object.Attribute{*expiration, *filename}))
+		require.EqualValues(t, 0, res["size"])
+		require.EqualValues(t, 100, res["expiration_epoch"])
+		require.Equal(t, map[string]interface{}{
+			v2object.SysAttributeExpEpoch: "100",
+			object.AttributeFileName:      "file.txt",
+		}, res["attributes"])
+	})
+}
+
+func TestAcceptsPutDetails(t *testing.T) {
+	for accept, expected := range map[string]bool{
+		"":                                     false,
+		"application/json":                     false,
+		"application/vnd.neofs.upload.v2+json": true,
+		"application/json, application/vnd.neofs.upload.v2+json; q=0.9": true,
+	} {
+		var c fasthttp.RequestCtx
+		c.Request.Header.Set(fasthttp.HeaderAccept, accept)
+		require.Equal(t, expected, acceptsPutDetails(&c), accept)
+	}
+}
+
+func TestUploadRaw(t *testing.T) {
+	u, neofs := newTestUploader(t)
+	idCnr := cidtest.ID()
+
+	newRequest := func(cnr, uri, body string, headers map[string]string) *fasthttp.RequestCtx {
+		c := new(fasthttp.RequestCtx)
+		c.Request.Header.DisableNormalizing()
+		c.Request.Header.SetMethod(fasthttp.MethodPut)
+		c.Request.SetRequestURI(uri)
+		c.Request.SetBodyString(body)
+		for key, val := range headers {
+			c.Request.Header.Set(key, val)
+		}
+		c.SetUserValue("cid", cnr)
+		return c
+	}
+
+	upload := func(t *testing.T, c *fasthttp.RequestCtx) testObject {
+		u.UploadRaw(c)
+		require.Equal(t, fasthttp.StatusOK, c.Response.StatusCode(), string(c.Response.Body()))
+
+		var res struct {
+			ObjectID    string `json:"object_id"`
+			ContainerID string `json:"container_id"`
+		}
+		require.NoError(t, json.Unmarshal(c.Response.Body(), &res))
+		require.Equal(t, idCnr.EncodeToString(), res.ContainerID)
+
+		var idObj oid.ID
+		require.NoError(t, idObj.DecodeString(res.ObjectID))
+		require.Equal(t, "/get/"+res.ContainerID+"/"+res.ObjectID, string(c.Response.Header.Peek(fasthttp.HeaderLocation)))
+		require.Contains(t, neofs.objects, idObj)
+		return neofs.objects[idObj]
+	}
+
+	t.Run("filename query", func(t *testing.T) {
+		obj := upload(t, newRequest(idCnr.EncodeToString(), "/upload/cid?filename=dir%2Ffile.txt", "content", nil))
+		require.Equal(t, "content", string(obj.payload))
+		require.Equal(t, map[string]string{
+			object.AttributeFileName: "file.txt",
+			object.AttributeFilePath: "dir/file.txt",
+		}, obj.attributes)
+	})
+
+	t.Run("header attributes", func(t *testing.T) {
+		obj := upload(t, newRequest(idCnr.EncodeToString(), "/upload/cid?filename=file.txt", "content", map[string]string{
+			"X-Attribute-MyAttribute": "value",
+			"X-Attribute-FileName":    "header.txt",
+		}))
+		require.Equal(t, map[string]string{
+			"MyAttribute":            "value",
+			object.AttributeFileName: "header.txt",
+		}, obj.attributes)
+	})
+
+	t.Run("empty body", func(t *testing.T) {
+		obj := upload(t, newRequest(idCnr.EncodeToString(), "/upload/cid", "", nil))
+		require.Empty(t, obj.payload)
+		require.Empty(t, obj.attributes)
+	})
+
+	t.Run("bad container id", func(t *testing.T) {
+		stored := len(neofs.objects)
+		c := newRequest("not a container", "/upload/cid", "content", nil)
+		u.UploadRaw(c)
+		require.Equal(t, fasthttp.StatusBadRequest, c.Response.StatusCode())
+		require.Len(t, neofs.objects, stored)
+	})
+
+	t.Run("duplicate attributes", func(t *testing.T) {
+		stored := len(neofs.objects)
+		c := newRequest(idCnr.EncodeToString(), "/upload/cid", "content", nil)
+		c.Request.Header.Add("X-Attribute-DupKey", "first-value")
+		c.Request.Header.Add("X-Attribute-DupKey", "second-value")
+		u.UploadRaw(c)
+		require.Equal(t, fasthttp.StatusBadRequest, c.Response.StatusCode())
+		require.Len(t, neofs.objects, stored)
+	})
+
+	t.Run("checksum", func(t *testing.T) {
+		// MD5 of "content"
+		obj := upload(t, newRequest(idCnr.EncodeToString(), "/upload/cid", "content", map[string]string{
+			hdrContentMD5: "mgNkuembtIDdJeHwKEyFVQ==",
+		}))
+		require.Equal(t, "content", string(obj.payload))
+	})
+
+	t.Run("checksum mismatch", func(t *testing.T) {
+		stored := len(neofs.objects)
+		c := newRequest(idCnr.EncodeToString(), "/upload/cid", "content", map[string]string{
+			hdrContentMD5: "AAAAAAAAAAAAAAAAAAAAAA==",
+		})
+		u.UploadRaw(c)
+		require.Equal(t, fasthttp.StatusBadRequest, c.Response.StatusCode())
+		require.Contains(t, string(c.Response.Body()), errChecksumMismatch.Error())
+		// the object is stored and removed then
+		require.Len(t, neofs.objects, stored)
+	})
+
+	t.Run("empty archive", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, zip.NewWriter(&buf).Close())
+
+		stored := len(neofs.objects)
+		c := newRequest(idCnr.EncodeToString(), "/upload/cid?extract=zip", buf.String(), nil)
+		u.UploadRaw(c)
+		require.Equal(t, fasthttp.StatusOK, c.Response.StatusCode(), string(c.Response.Body()))
+		require.JSONEq(t, `{"container_id":"`+idCnr.EncodeToString()+`","objects":[]}`, string(c.Response.Body()))
+		require.Len(t, neofs.objects, stored)
+	})
+}
+
+func TestMultiPutStatus(t *testing.T) {
+	stored := partPutResponse{FileName: "stored.txt", ObjectID: "id"}
+	failed := partPutResponse{FileName: "failed.txt", Error: "error"}
+
+	require.Equal(t, fasthttp.StatusOK, multiPutStatus([]partPutResponse{stored, stored}))
+	require.Equal(t, fasthttp.StatusMultiStatus, multiPutStatus([]partPutResponse{stored, failed}))
+	require.Equal(t, fasthttp.StatusBadRequest, multiPutStatus([]partPutResponse{failed, failed}))
+	require.Equal(t, fasthttp.StatusOK, multiPutStatus(nil))
+}
+
+func TestUpload(t *testing.T) {
+	u, neofs := newTestUploader(t)
+	idCnr := cidtest.ID()
+
+	type part struct {
+		filename string
+		md5      string
+	}
+
+	newRequest := func(parts ...part) *fasthttp.RequestCtx {
+		var body bytes.Buffer
+		mw := multipart.NewWriter(&body)
+		for _, p := range parts {
+			header := textproto.MIMEHeader{}
+			header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="file"; filename="%s"`, p.filename))
+			if p.md5 != "" {
+				header.Set(hdrContentMD5, p.md5)
+			}
+			w, err := mw.CreatePart(header)
+			require.NoError(t, err)
+			_, err = w.Write([]byte("content of " + p.filename))
+			require.NoError(t, err)
+		}
+		require.NoError(t, mw.Close())
+
+		c := new(fasthttp.RequestCtx)
+		c.Request.Header.SetMethod(fasthttp.MethodPost)
+		c.Request.Header.SetContentType(mw.FormDataContentType())
+		c.Request.SetRequestURI("/upload/cid")
+		c.Request.SetBodyStream(bytes.NewReader(body.Bytes()), body.Len())
+		c.SetUserValue("cid", idCnr.EncodeToString())
+		return c
+	}
+
+	decode := func(t *testing.T, c *fasthttp.RequestCtx) multiPutResponse {
+		var res multiPutResponse
+		require.NoError(t, json.Unmarshal(c.Response.Body(), &res))
+		require.Equal(t, idCnr.EncodeToString(), res.ContainerID)
+		return res
+	}
+
+	t.Run("all stored", func(t *testing.T) {
+		c := newRequest(part{filename: "a.txt"}, part{filename: "b.txt"})
+		u.Upload(c)
+		require.Equal(t, fasthttp.StatusOK, c.Response.StatusCode(), string(c.Response.Body()))
+
+		res := decode(t, c)
+		require.Len(t, res.Objects, 2)
+		for _, obj := range res.Objects {
+			require.Empty(t, obj.Error)
+			var idObj oid.ID
+			require.NoError(t, idObj.DecodeString(obj.ObjectID))
+			require.Equal(t, "content of "+obj.FileName, string(neofs.objects[idObj].payload))
+		}
+	})
+
+	t.Run("partially stored", func(t *testing.T) {
+		c := newRequest(part{filename: "a.txt"}, part{filename: "b.txt", md5: "invalid"})
+		u.Upload(c)
+		require.Equal(t, fasthttp.StatusMultiStatus, c.Response.StatusCode(), string(c.Response.Body()))
+
+		res := decode(t, c)
+		require.Len(t, res.Objects, 2)
+		require.Empty(t, res.Objects[0].Error)
+		require.NotEmpty(t, res.Objects[0].ObjectID)
+		require.Equal(t, "b.txt", res.Objects[1].FileName)
+		require.NotEmpty(t, res.Objects[1].Error)
+		require.Empty(t, res.Objects[1].ObjectID)
+	})
+
+	t.Run("checksum mismatch", func(t *testing.T) {
+		stored := len(neofs.objects)
+		c := newRequest(part{filename: "a.txt"}, part{filename: "b.txt", md5: "AAAAAAAAAAAAAAAAAAAAAA=="})
+		u.Upload(c)
+		require.Equal(t, fasthttp.StatusMultiStatus, c.Response.StatusCode(), string(c.Response.Body()))
+
+		res := decode(t, c)
+		require.Len(t, res.Objects, 2)
+		require.Empty(t, res.Objects[0].Error)
+		require.Contains(t, res.Objects[1].Error, errChecksumMismatch.Error())
+		require.Empty(t, res.Objects[1].ObjectID)
+		require.Len(t, neofs.objects, stored+1)
+	})
+
+	t.Run("nothing stored", func(t *testing.T) {
+		c := newRequest(part{filename: "a.txt", md5: "invalid"}, part{filename: "b.txt", md5: "invalid"})
+		u.Upload(c)
+		require.Equal(t, fasthttp.StatusBadRequest, c.Response.StatusCode(), string(c.Response.Body()))
+		require.Len(t, decode(t, c).Objects, 2)
+	})
+}